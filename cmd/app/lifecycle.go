@@ -0,0 +1,39 @@
+package main
+
+import (
+	"BirthdayGreetings/internal/logging"
+	"context"
+	"errors"
+)
+
+// shutdownHook - шаг остановки приложения.
+type shutdownHook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// lifecycle хранит шаги остановки компонентов приложения. Шаги выполняются
+// в порядке регистрации, поэтому сначала регистрируются компоненты, которые
+// принимают новую работу, а в конце - ресурсы, которыми они пользуются.
+type lifecycle struct {
+	hooks []shutdownHook
+}
+
+func (l *lifecycle) onShutdown(name string, stop func(ctx context.Context) error) {
+	l.hooks = append(l.hooks, shutdownHook{name: name, stop: stop})
+}
+
+// shutdown выполняет все шаги остановки. Ошибка одного шага не мешает
+// остальным, но возвращается вызывающему. Все шаги делят один ctx, поэтому
+// остановка в целом укладывается в его дедлайн.
+func (l *lifecycle) shutdown(ctx context.Context) error {
+	var errs []error
+	for _, hook := range l.hooks {
+		logging.Logger.Printf("Остановка: %s", hook.name)
+		if err := hook.stop(ctx); err != nil {
+			logging.Logger.Printf("Ошибка при остановке %s: %v", hook.name, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
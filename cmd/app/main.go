@@ -1,59 +1,128 @@
 package main
 
 import (
+	"BirthdayGreetings/internal/account"
+	"BirthdayGreetings/internal/admin"
+	"BirthdayGreetings/internal/audit"
 	"BirthdayGreetings/internal/auth"
+	"BirthdayGreetings/internal/birthday"
 	"BirthdayGreetings/internal/bot"
+	"BirthdayGreetings/internal/channel"
+	"BirthdayGreetings/internal/collection"
+	"BirthdayGreetings/internal/config"
 	"BirthdayGreetings/internal/db"
 	"BirthdayGreetings/internal/logging"
 	"BirthdayGreetings/internal/notification"
+	"BirthdayGreetings/internal/reminder"
 	"BirthdayGreetings/internal/service"
+	"BirthdayGreetings/internal/session"
 	"BirthdayGreetings/internal/subscription"
+	"BirthdayGreetings/internal/team"
 	"BirthdayGreetings/internal/telegram"
+	"BirthdayGreetings/internal/wish"
+	"BirthdayGreetings/internal/wishlist"
+	"context"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
-	"github.com/joho/godotenv"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 func main() {
 
-	err := godotenv.Load()
+	logging.Init("logs/app.log")
+
+	cfg, err := config.Load()
 	if err != nil {
-		logging.Logger.Fatalf("Error loading .env file: %v", err)
+		logging.Logger.Fatalf("Ошибка загрузки настроек: %v", err)
 	}
 
-	logging.Init("logs/app.log")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg.Postgres, os.Args[2:]))
+	}
 
-	err = db.Connect()
-	if err != nil {
-		logging.Logger.Fatalf("could not connect to the database: %v", err)
+	if err := cfg.Validate(); err != nil {
+		logging.Logger.Fatalf("Ошибка в настройках:\n%v", err)
 	}
 
-	appID, err := strconv.Atoi(os.Getenv("TELEGRAM_APP_ID"))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err = db.Connect(cfg.Postgres)
 	if err != nil {
-		logging.Logger.Fatalf("Ошибка парсинга TELEGRAM_APP_ID: %v", err)
+		logging.Logger.Fatalf("could not connect to the database: %v", err)
 	}
-	appHash := os.Getenv("TELEGRAM_APP_HASH")
-	phoneNumber := os.Getenv("TELEGRAM_PHONE_NUMBER")
-	password := os.Getenv("TELEGRAM_PASSWORD")
 
-	subscriptionService := subscription.NewSubscriptionService()
-	userService := service.NewUserService()
-	authService := auth.NewAuthService(userService)
-	telegramClient, err := telegram.NewClient(appID, appHash, phoneNumber, password)
+	matcher := birthday.NewMatcher(cfg.LeapDayPolicy)
+
+	sessionService := session.NewSessionService(db.NewPostgresSessionRepository(db.DB), cfg.Session.IdleTimeout, cfg.Session.AbsoluteTimeout)
+	go sessionService.RunCleanup(ctx, time.Hour)
+
+	auditService := audit.NewAuditService(db.NewPostgresAuditRepository(db.DB))
+	subscriptionService := subscription.NewSubscriptionService(db.NewPostgresSubscriptionRepository(db.DB), auditService)
+	userService := service.NewUserService(db.NewPostgresUserRepository(db.DB), auditService)
+	reminderService := reminder.NewReminderService(db.NewPostgresReminderRepository(db.DB))
+	loginLimiter := auth.NewLoginLimiter(db.NewPostgresLoginAttemptRepository(db.DB), cfg.Login.MaxAttempts, cfg.Login.LockoutBase, cfg.Login.LockoutMax)
+	go loginLimiter.RunCleanup(ctx, time.Hour)
+	authService := auth.NewAuthService(userService, loginLimiter, db.NewPostgresRecoveryCodeRepository(db.DB), auditService, cfg.Login.Mode)
+	adminService := admin.NewAdminService(userService, sessionService, loginLimiter, auditService, cfg.Bot.AdminID)
+	teamService := team.NewTeamService(db.NewPostgresTeamRepository(db.DB), userService, auditService)
+	wishService := wish.NewWishService(db.NewPostgresWishRepository(db.DB), userService, matcher)
+	wishlistService := wishlist.NewWishlistService(db.NewPostgresWishlistRepository(db.DB), userService, subscriptionService)
+	collectionService := collection.NewCollectionService(db.NewPostgresCollectionRepository(db.DB), subscriptionService, cfg.Collection.DaysBefore)
+	accountService := account.NewAccountService(userService, subscriptionService, reminderService, teamService, db.NewPostgresNotificationRepository(db.DB), loginLimiter, auditService)
+	if err := adminService.BootstrapOwner(); err != nil {
+		logging.Logger.Printf("Не удалось назначить владельца: %v", err)
+	}
+	telegramClient, err := telegram.NewClient(cfg.Telegram)
 	if err != nil {
 		logging.Logger.Fatalf("ошибка в создании telegram_client: %v", err)
 	}
 
-	botService, err := bot.NewBotService(authService, userService, subscriptionService, telegramClient)
+	botAPI, err := tgbotapi.NewBotAPI(cfg.Bot.Token)
+	if err != nil {
+		logging.Logger.Fatalf("ошибка в подключении к Telegram Bot API: %v", err)
+	}
+	botService, err := bot.NewBotService(botAPI, authService, userService, subscriptionService, reminderService, matcher, sessionService, adminService, accountService, teamService, collectionService, wishService, wishlistService, db.NewPostgresDialogRepository(db.DB), cfg.Bot, telegramClient)
 	if err != nil {
 		logging.Logger.Fatalf("ошибка в создании bot service: %v", err)
 	}
-	go botService.Start()
+	go botService.RunDialogCleanup(ctx, time.Minute)
+
+	channelService := channel.NewChannelService(db.NewPostgresChannelRepository(db.DB), telegramClient, botService.GetBotID())
+	notificationService := notification.NewNotificationService(userService, subscriptionService, reminderService, db.NewPostgresNotificationRepository(db.DB), matcher, botService, channelService, teamService, collectionService, wishService, wishlistService)
+	adminService.SetNotificationRunner(notificationService)
+	botService.SetWishDeliverer(notificationService)
+	notificationService.StartCronJobs(ctx)
+	go func() {
+		if err := botService.Start(); err != nil {
+			logging.Logger.Printf("Бот остановлен с ошибкой: %v", err)
+			stop()
+		}
+	}()
 
-	notificationService := notification.NewNotificationService(userService, botService, telegramClient)
-	notificationService.StartCronJobs()
+	app := &lifecycle{}
+	app.onShutdown("бот", botService.Stop)
+	app.onShutdown("уведомления", notificationService.StopCronJobs)
+	app.onShutdown("telegram клиент", telegramClient.Close)
+	app.onShutdown("база данных", func(context.Context) error {
+		return db.DB.Close()
+	})
 
-	select {}
+	logging.Logger.Println("Приложение запущено.")
+	<-ctx.Done()
+	stop()
+	logging.Logger.Println("Получен сигнал остановки, завершение работы...")
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := app.shutdown(shutdownCtx); err != nil {
+		logging.Logger.Printf("Приложение остановлено с ошибками: %v", err)
+		cancel()
+		os.Exit(1)
+	}
+	logging.Logger.Println("Приложение остановлено.")
 }
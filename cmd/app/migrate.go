@@ -0,0 +1,78 @@
+package main
+
+import (
+	"BirthdayGreetings/internal/config"
+	"BirthdayGreetings/internal/db"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `Использование: app migrate <команда>
+
+Команды:
+  up          применить все новые миграции
+  down [N]    откатить N последних миграций (по умолчанию 1)
+  status      показать состояние миграций`
+
+func runMigrate(cfg config.Postgres, args []string) int {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "ошибка в настройках базы данных:\n%v\n", err)
+		return 1
+	}
+
+	if err := db.Open(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "не удалось подключиться к базе данных: %v\n", err)
+		return 1
+	}
+	defer db.DB.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(db.DB)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ошибка применения миграций: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Применено миграций: %d\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "некорректное количество миграций: %s\n", args[1])
+				return 2
+			}
+			steps = n
+		}
+		rolledBack, err := db.MigrateDown(db.DB, steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ошибка отката миграций: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Откачено миграций: %d\n", rolledBack)
+	case "status":
+		statuses, err := db.GetMigrationsStatus(db.DB)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ошибка получения состояния миграций: %v\n", err)
+			return 1
+		}
+		for _, st := range statuses {
+			state := "не применена"
+			if st.Applied {
+				state = "применена " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", st.Version, st.Name, state)
+		}
+	default:
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	return 0
+}
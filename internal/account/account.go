@@ -0,0 +1,173 @@
+package account
+
+import (
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/auth"
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/reminder"
+	"BirthdayGreetings/internal/service"
+	"BirthdayGreetings/internal/subscription"
+	"BirthdayGreetings/internal/team"
+	"time"
+)
+
+// Export - все данные, которые сервис хранит о пользователе.
+type Export struct {
+	ExportedAt      time.Time      `json:"exported_at"`
+	Profile         Profile        `json:"profile"`
+	ReminderOffsets []int          `json:"reminder_offsets"`
+	Subscriptions   []Contact      `json:"subscriptions"`
+	Subscribers     []Contact      `json:"subscribers"`
+	Teams           []string       `json:"teams"`
+	Notifications   []Notification `json:"notifications"`
+}
+
+// Profile - данные аккаунта. Хэши пароля и PIN-кода не выгружаются,
+// вместо них указано, заданы ли они.
+type Profile struct {
+	ID          int64       `json:"id"`
+	Username    string      `json:"username"`
+	TelegramID  int64       `json:"telegram_id"`
+	Birthday    string      `json:"birthday,omitempty"`
+	Timezone    string      `json:"timezone,omitempty"`
+	NotifyTime  string      `json:"notify_time"`
+	Role        models.Role `json:"role"`
+	Banned      bool        `json:"banned"`
+	HasPassword bool        `json:"has_password"`
+	HasPIN      bool        `json:"has_pin"`
+}
+
+// Contact - пользователь из подписок или подписчиков.
+type Contact struct {
+	Username string `json:"username"`
+	Birthday string `json:"birthday,omitempty"`
+}
+
+type Notification struct {
+	Kind    string    `json:"kind"`
+	Date    string    `json:"date"`
+	Message string    `json:"message"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// AccountService выгружает и удаляет данные пользователя по его запросу.
+type AccountService struct {
+	userService *service.UserService
+	subService  *subscription.SubscriptionService
+	reminders   *reminder.ReminderService
+	teams       *team.TeamService
+	history     db.NotificationRepository
+	limiter     *auth.LoginLimiter
+	audit       *audit.AuditService
+}
+
+func NewAccountService(userService *service.UserService, subService *subscription.SubscriptionService, reminders *reminder.ReminderService, teams *team.TeamService, history db.NotificationRepository, limiter *auth.LoginLimiter, auditService *audit.AuditService) *AccountService {
+	return &AccountService{
+		userService: userService,
+		subService:  subService,
+		reminders:   reminders,
+		teams:       teams,
+		history:     history,
+		limiter:     limiter,
+		audit:       auditService,
+	}
+}
+
+// Export собирает профиль пользователя, его подписки, подписчиков, команды и журнал уведомлений.
+func (s *AccountService) Export(user *models.User) (*Export, error) {
+	export := &Export{
+		ExportedAt: time.Now(),
+		Profile: Profile{
+			ID:          user.ID,
+			Username:    user.Username,
+			TelegramID:  user.TelegramID,
+			Birthday:    formatBirthday(user.Birthday),
+			Timezone:    user.Timezone,
+			NotifyTime:  user.NotifyTime,
+			Role:        user.Role,
+			Banned:      user.Banned,
+			HasPassword: user.Password != "",
+			HasPIN:      user.PIN != "",
+		},
+		Subscriptions: []Contact{},
+		Subscribers:   []Contact{},
+		Teams:         []string{},
+		Notifications: []Notification{},
+	}
+
+	offsets, err := s.reminders.GetOffsets(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.ReminderOffsets = offsets
+
+	subscriptions, err := s.subService.GetSubscriptions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subscriptions {
+		export.Subscriptions = append(export.Subscriptions, Contact{Username: sub.Username, Birthday: formatBirthday(sub.Birthday)})
+	}
+
+	subscribers, err := s.subService.GetSubscribersOf(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subscribers {
+		export.Subscribers = append(export.Subscribers, Contact{Username: sub.Username})
+	}
+
+	teams, err := s.teams.UserTeams(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range teams {
+		export.Teams = append(export.Teams, t.Name)
+	}
+
+	notifications, err := s.history.GetNotifications(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notifications {
+		export.Notifications = append(export.Notifications, Notification{
+			Kind:    n.Kind,
+			Date:    n.LocalDate.Format("2006-01-02"),
+			Message: n.Message,
+			SentAt:  n.SentAt,
+		})
+	}
+
+	s.audit.Record(audit.EventDataExported, user.ID, user.ID, "")
+	return export, nil
+}
+
+// Delete удаляет аккаунт пользователя вместе с подписками, участием в командах,
+// сессиями, напоминаниями и журналом уведомлений. В журнале аудита события пользователя остаются
+// без ссылки на него, а само удаление записывается без имени пользователя.
+// Команды, где пользователь был единственным администратором, переходят к
+// участнику с наибольшим стажем.
+func (s *AccountService) Delete(user *models.User) error {
+	if err := s.teams.HandOver(user); err != nil {
+		return err
+	}
+	if err := s.userService.DeleteUser(user.ID); err != nil {
+		return err
+	}
+	if _, err := s.limiter.Reset(user.TelegramID); err != nil {
+		logging.Logger.Printf("Ошибка в сбросе попыток входа %s: %v", user.Username, err)
+	}
+
+	s.audit.Record(audit.EventAccountDeleted, 0, 0, "")
+	return nil
+}
+
+func formatBirthday(date time.Time) string {
+	if !birthday.IsSet(date) {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
@@ -0,0 +1,302 @@
+package admin
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/auth"
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/service"
+	"BirthdayGreetings/internal/session"
+)
+
+const (
+	tempPasswordLength   = 12
+	tempPasswordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// NotificationRunner запускает ежедневную рассылку вне расписания.
+type NotificationRunner interface {
+	RunDaily() error
+}
+
+// AdminService выполняет действия администраторов и записывает каждое из них
+// в журнал аудита. Пользователей он меняет через UserService методами, которые
+// не записывают событий самого пользователя, поэтому в журнал попадает одно
+// событие администратора.
+//
+// Пользователь с telegram ID владельца (ADMIN_ID) всегда считается владельцем,
+// даже если зарегистрировался после запуска бота.
+type AdminService struct {
+	users           *service.UserService
+	sessions        *session.SessionService
+	limiter         *auth.LoginLimiter
+	audit           *audit.AuditService
+	ownerTelegramID int64
+	notifications   NotificationRunner
+}
+
+func NewAdminService(users *service.UserService, sessions *session.SessionService, limiter *auth.LoginLimiter, auditService *audit.AuditService, ownerTelegramID int64) *AdminService {
+	return &AdminService{
+		users:           users,
+		sessions:        sessions,
+		limiter:         limiter,
+		audit:           auditService,
+		ownerTelegramID: ownerTelegramID,
+	}
+}
+
+// SetNotificationRunner задаёт рассылку для RunNotifications. Сервис уведомлений
+// создаётся после бота, поэтому передаётся отдельно.
+func (s *AdminService) SetNotificationRunner(runner NotificationRunner) {
+	s.notifications = runner
+}
+
+// RoleOf возвращает действующую роль пользователя.
+func (s *AdminService) RoleOf(user *models.User) models.Role {
+	if user.TelegramID == s.ownerTelegramID {
+		return models.RoleOwner
+	}
+	if user.Role == "" {
+		return models.RoleUser
+	}
+	return user.Role
+}
+
+// BootstrapOwner сохраняет роль владельца за пользователем с ADMIN_ID, если он уже зарегистрирован.
+func (s *AdminService) BootstrapOwner() error {
+	owner, err := s.users.GetUserByTgID(s.ownerTelegramID)
+	if err != nil {
+		return nil
+	}
+	if owner.Role == models.RoleOwner {
+		return nil
+	}
+	return s.users.SetUserRole(owner.ID, models.RoleOwner)
+}
+
+func (s *AdminService) ListUsers() ([]*models.UserBirthLayout, error) {
+	users, err := s.users.GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.TelegramID == s.ownerTelegramID {
+			user.Role = models.RoleOwner
+		}
+	}
+	return users, nil
+}
+
+// Ban блокирует пользователя и завершает все его сессии.
+func (s *AdminService) Ban(actor *models.User, username string) error {
+	target, err := s.target(actor, username)
+	if err != nil {
+		return err
+	}
+	if target.Banned {
+		return errors.New(400, "пользователь уже заблокирован")
+	}
+
+	if err := s.users.SetUserBanned(target.ID, true); err != nil {
+		return err
+	}
+	revoked, err := s.sessions.RevokeAll(target.ID)
+	if err != nil {
+		logging.Logger.Printf("Ошибка в завершении сессий пользователя %s: %v", target.Username, err)
+	}
+
+	s.record(actor, audit.EventAdminBan, target, fmt.Sprintf("завершено сессий: %d", revoked))
+	return nil
+}
+
+func (s *AdminService) Unban(actor *models.User, username string) error {
+	target, err := s.target(actor, username)
+	if err != nil {
+		return err
+	}
+	if !target.Banned {
+		return errors.New(400, "пользователь не заблокирован")
+	}
+
+	if err := s.users.SetUserBanned(target.ID, false); err != nil {
+		return err
+	}
+
+	s.record(actor, audit.EventAdminUnban, target, "")
+	return nil
+}
+
+// Unlock снимает блокировку входа после неудачных попыток с Telegram аккаунта пользователя.
+func (s *AdminService) Unlock(actor *models.User, username string) error {
+	target, err := s.target(actor, username)
+	if err != nil {
+		return err
+	}
+
+	unlocked, err := s.limiter.Reset(target.TelegramID)
+	if err != nil {
+		return err
+	}
+	if !unlocked {
+		return errors.New(400, "у пользователя нет неудачных попыток входа")
+	}
+
+	s.record(actor, audit.EventAdminUnlock, target, "")
+	return nil
+}
+
+// ResetPassword задаёт пользователю случайный временный пароль, завершает его
+// сессии и возвращает пользователя и новый пароль.
+func (s *AdminService) ResetPassword(actor *models.User, username string) (*models.User, string, error) {
+	target, err := s.target(actor, username)
+	if err != nil {
+		return nil, "", err
+	}
+
+	password, err := tempPassword()
+	if err != nil {
+		return nil, "", errors.New(500, fmt.Sprintf("не удалось создать пароль: %v", err))
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, "", errors.New(500, fmt.Sprintf("не удалось хэшировать пароль: %v", err))
+	}
+
+	if err := s.users.SetUserPassword(target.ID, hash); err != nil {
+		return nil, "", err
+	}
+	if _, err := s.sessions.RevokeAll(target.ID); err != nil {
+		logging.Logger.Printf("Ошибка в завершении сессий пользователя %s: %v", target.Username, err)
+	}
+
+	s.record(actor, audit.EventAdminResetPassword, target, "")
+	return target, password, nil
+}
+
+// SetBirthday меняет дату рождения пользователя в формате YYYY-MM-DD.
+func (s *AdminService) SetBirthday(actor *models.User, username, date string) error {
+	target, err := s.target(actor, username)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.ChangeBirthday(target, date); err != nil {
+		return err
+	}
+
+	previous := "не указана"
+	if birthday.IsSet(target.Birthday) {
+		previous = target.Birthday.Format("2006-01-02")
+	}
+	s.record(actor, audit.EventAdminSetBirthday, target, fmt.Sprintf("%s -> %s", previous, date))
+	return nil
+}
+
+// RunNotifications запускает ежедневную рассылку. Уже отправленные сегодня
+// напоминания повторно не отправляются.
+func (s *AdminService) RunNotifications(actor *models.User) error {
+	if !s.RoleOf(actor).AtLeast(models.RoleAdmin) {
+		return errors.New(403, "недостаточно прав")
+	}
+	if s.notifications == nil {
+		return errors.New(503, "рассылка недоступна")
+	}
+
+	err := s.notifications.RunDaily()
+	details := "успешно"
+	if err != nil {
+		details = "ошибка: " + err.Error()
+	}
+	s.record(actor, audit.EventAdminRunNotifications, nil, details)
+	return err
+}
+
+// Promote назначает пользователя администратором. Доступно только владельцу.
+func (s *AdminService) Promote(actor *models.User, username string) error {
+	return s.changeRole(actor, username, models.RoleAdmin, audit.EventAdminPromote)
+}
+
+// Demote снимает с пользователя права администратора. Доступно только владельцу.
+func (s *AdminService) Demote(actor *models.User, username string) error {
+	return s.changeRole(actor, username, models.RoleUser, audit.EventAdminDemote)
+}
+
+func (s *AdminService) changeRole(actor *models.User, username string, role models.Role, eventType string) error {
+	if s.RoleOf(actor) != models.RoleOwner {
+		return errors.New(403, "назначать администраторов может только владелец")
+	}
+
+	target, err := s.target(actor, username)
+	if err != nil {
+		return err
+	}
+	previous := s.RoleOf(target)
+	if previous == role {
+		return errors.New(400, fmt.Sprintf("у пользователя уже роль %s", role))
+	}
+
+	if err := s.users.SetUserRole(target.ID, role); err != nil {
+		return err
+	}
+
+	s.record(actor, eventType, target, fmt.Sprintf("%s -> %s", previous, role))
+	return nil
+}
+
+// target находит пользователя, над которым выполняется действие. Действовать
+// можно только над пользователями с ролью младше своей и не над собой.
+func (s *AdminService) target(actor *models.User, username string) (*models.User, error) {
+	actorRole := s.RoleOf(actor)
+	if !actorRole.AtLeast(models.RoleAdmin) {
+		return nil, errors.New(403, "недостаточно прав")
+	}
+
+	target, err := s.users.GetUserByName(username)
+	if err != nil {
+		return nil, err
+	}
+	if target.ID == actor.ID {
+		return nil, errors.New(400, "нельзя выполнить это действие над собой")
+	}
+	if !actorRole.Above(s.RoleOf(target)) {
+		return nil, errors.New(403, "недостаточно прав для действий над этим пользователем")
+	}
+	return target, nil
+}
+
+// record записывает действие администратора в журнал аудита.
+func (s *AdminService) record(actor *models.User, eventType string, target *models.User, details string) {
+	var targetID int64
+	if target != nil {
+		targetID = target.ID
+	}
+	s.audit.Record(eventType, actor.ID, targetID, details)
+	logging.Logger.Printf("Администратор %s выполнил %s (цель %d): %s", actor.Username, eventType, targetID, details)
+}
+
+// Events возвращает журнал аудита. Доступно администраторам.
+func (s *AdminService) Events(actor *models.User, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	if !s.RoleOf(actor).AtLeast(models.RoleAdmin) {
+		return nil, errors.New(403, "недостаточно прав")
+	}
+	return s.audit.Events(filter)
+}
+
+func tempPassword() (string, error) {
+	password := make([]byte, tempPasswordLength)
+	max := big.NewInt(int64(len(tempPasswordAlphabet)))
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = tempPasswordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+)
+
+// Типы событий. Часть до точки - категория, по ней можно фильтровать журнал.
+const (
+	EventRegister    = "auth.register"
+	EventLogin       = "auth.login"
+	EventLoginFailed = "auth.login_failed"
+	EventLoginLocked = "auth.login_locked"
+
+	EventPasswordChanged   = "auth.password_changed"
+	EventRecoveryRequested = "auth.recovery_requested"
+	EventRecoveryFailed    = "auth.recovery_failed"
+	EventPasswordRecovered = "auth.password_recovered"
+
+	EventBirthdayChanged   = "user.birthday_changed"
+	EventTimezoneChanged   = "user.timezone_changed"
+	EventNotifyTimeChanged = "user.notify_time_changed"
+	EventPINChanged        = "user.pin_changed"
+	EventDataExported      = "user.data_exported"
+	EventAccountDeleted    = "user.deleted"
+
+	EventSubscribed   = "subscription.created"
+	EventUnsubscribed = "subscription.deleted"
+
+	EventTeamCreated       = "team.created"
+	EventTeamJoined        = "team.joined"
+	EventTeamLeft          = "team.left"
+	EventTeamMemberRemoved = "team.member_removed"
+	EventTeamAdminGranted  = "team.admin_granted"
+
+	EventAdminBan              = "admin.ban"
+	EventAdminUnban            = "admin.unban"
+	EventAdminResetPassword    = "admin.reset_password"
+	EventAdminSetBirthday      = "admin.set_birthday"
+	EventAdminRunNotifications = "admin.run_notifications"
+	EventAdminPromote          = "admin.promote"
+	EventAdminDemote           = "admin.demote"
+	EventAdminUnlock           = "admin.unlock"
+)
+
+// AuditService записывает изменения аккаунтов и подписок в журнал аудита.
+type AuditService struct {
+	repo db.AuditRepository
+}
+
+func NewAuditService(repo db.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record записывает событие: actorID совершил eventType над userID. Нулевой ID
+// означает, что участник неизвестен. Ошибка записи не должна прерывать само
+// действие, поэтому она только логируется.
+func (s *AuditService) Record(eventType string, actorID, userID int64, details string) {
+	event := &models.AuditEvent{
+		Type:    eventType,
+		ActorID: actorID,
+		UserID:  userID,
+		Details: details,
+	}
+	if err := s.repo.RecordEvent(event); err != nil {
+		logging.Logger.Printf("Ошибка в записи события аудита %s: %v", eventType, err)
+	}
+}
+
+// Events возвращает события журнала, начиная с новых.
+func (s *AuditService) Events(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	return s.repo.GetEvents(filter)
+}
@@ -1,7 +1,11 @@
 package auth
 
 import (
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/config"
+	"BirthdayGreetings/internal/db"
 	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
 	"BirthdayGreetings/internal/models"
 	"BirthdayGreetings/internal/service"
 	"fmt"
@@ -9,16 +13,39 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// errLoginFailed - единая ошибка неудачного входа: по ней нельзя понять,
+// существует ли пользователь и к какому Telegram аккаунту он привязан.
+var errLoginFailed = errors.New(401, "неверный логин и/или пароль")
+
+// dummyHash сравнивается с паролем, когда пользователь не найден, чтобы ответ
+// на несуществующее имя занимал столько же времени, сколько на неверный пароль.
+var dummyHash, _ = HashPassword("dummy password")
+
 type AuthService struct {
-	userService *service.UserService
+	userService   *service.UserService
+	limiter       *LoginLimiter
+	recoveryCodes db.RecoveryCodeRepository
+	audit         *audit.AuditService
+	mode          string
 }
 
-func NewAuthService(userService *service.UserService) *AuthService {
+// NewAuthService создаёт сервис входа. mode - способ входа, config.LoginPassword
+// или config.LoginTelegram.
+func NewAuthService(userService *service.UserService, limiter *LoginLimiter, recoveryCodes db.RecoveryCodeRepository, auditService *audit.AuditService, mode string) *AuthService {
 	return &AuthService{
-		userService: userService,
+		userService:   userService,
+		limiter:       limiter,
+		recoveryCodes: recoveryCodes,
+		audit:         auditService,
+		mode:          mode,
 	}
 }
 
+// Passwordless сообщает, что пользователи входят по Telegram аккаунту без пароля.
+func (s *AuthService) Passwordless() bool {
+	return s.mode == config.LoginTelegram
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -29,10 +56,22 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
+// RegisterUser регистрирует пользователя. При входе без пароля password
+// может быть пустым, тогда пароль не сохраняется.
 func (s *AuthService) RegisterUser(username, password string, telegramID int64) error {
-	hashedPassword, err := HashPassword(password)
-	if err != nil {
-		return errors.New(401, fmt.Sprintf("не удалось хэшировать пароль: %v", err))
+	var hashedPassword string
+	switch {
+	case password != "":
+		if err := ValidatePassword(username, password); err != nil {
+			return err
+		}
+		hash, err := HashPassword(password)
+		if err != nil {
+			return errors.New(401, fmt.Sprintf("не удалось хэшировать пароль: %v", err))
+		}
+		hashedPassword = hash
+	case !s.Passwordless():
+		return errors.New(400, "пароль не может быть пустым")
 	}
 
 	user := &models.User{
@@ -41,22 +80,58 @@ func (s *AuthService) RegisterUser(username, password string, telegramID int64)
 		TelegramID: telegramID,
 	}
 
-	return s.userService.CreateUser(user)
+	if err := s.userService.CreateUser(user); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventRegister, user.ID, user.ID, fmt.Sprintf("telegram_id %d", telegramID))
+	return nil
 }
 
+// AuthenticateUser проверяет имя, пароль и Telegram аккаунт пользователя.
+// Неудачные попытки считаются по Telegram ID, после нескольких неудач вход
+// с этого аккаунта временно блокируется.
 func (s *AuthService) AuthenticateUser(username, password string, telegramID int64) (string, error) {
+	if err := s.limiter.Check(telegramID); err != nil {
+		s.audit.Record(audit.EventLoginFailed, 0, 0, fmt.Sprintf("вход заблокирован, username %q, telegram_id %d", username, telegramID))
+		return "", err
+	}
+
 	user, err := s.userService.GetUserByName(username)
 	if err != nil {
-		return "", err
+		CheckPasswordHash(password, dummyHash)
+		s.fail(username, telegramID, 0, fmt.Sprintf("неизвестный username %q, telegram_id %d", username, telegramID))
+		return "", errLoginFailed
 	}
 
 	if user.TelegramID != telegramID {
-		return "", errors.New(401, "неверный телеграмм аккаунт")
+		CheckPasswordHash(password, dummyHash)
+		s.fail(username, telegramID, user.ID, fmt.Sprintf("чужой телеграмм аккаунт, telegram_id %d", telegramID))
+		return "", errLoginFailed
 	}
 
 	if !CheckPasswordHash(password, user.Password) {
-		return "", errors.New(401, "неверный логин и/или пароль")
+		s.fail(username, telegramID, user.ID, "неверный пароль")
+		return "", errLoginFailed
 	}
 
+	if user.Banned {
+		s.audit.Record(audit.EventLoginFailed, user.ID, user.ID, "аккаунт заблокирован")
+		return "", errors.New(403, "аккаунт заблокирован")
+	}
+
+	if _, err := s.limiter.Reset(telegramID); err != nil {
+		logging.Logger.Printf("Ошибка в сбросе попыток входа %s: %v", username, err)
+	}
+	s.audit.Record(audit.EventLogin, user.ID, user.ID, "")
+
 	return username, nil
 }
+
+// fail записывает неудачную попытку входа в счётчики и журнал аудита.
+func (s *AuthService) fail(username string, telegramID, userID int64, details string) {
+	s.audit.Record(audit.EventLoginFailed, 0, userID, details)
+	if lockout := s.limiter.Fail(telegramID); lockout > 0 {
+		s.audit.Record(audit.EventLoginLocked, 0, userID, fmt.Sprintf("username %q, telegram_id %d, на %s", username, telegramID, lockout))
+	}
+}
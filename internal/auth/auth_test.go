@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/config"
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/service"
+)
+
+const (
+	testPassword    = "secret123"
+	testTelegramID  = 100
+	testMaxAttempts = 3
+)
+
+func TestMain(m *testing.M) {
+	logging.Logger = log.New(io.Discard, "", 0)
+	os.Exit(m.Run())
+}
+
+// newTestAuthService создаёт сервис входа в памяти с пользователем alice,
+// привязанным к testTelegramID. В режиме config.LoginPassword у alice пароль testPassword.
+func newTestAuthService(t *testing.T, mode string) (*AuthService, *service.UserService) {
+	t.Helper()
+	users := db.NewMemoryUserRepository()
+	auditService := audit.NewAuditService(db.NewMemoryAuditRepository(users))
+	userService := service.NewUserService(users, auditService)
+	limiter := NewLoginLimiter(db.NewMemoryLoginAttemptRepository(), testMaxAttempts, time.Minute, time.Hour)
+	s := NewAuthService(userService, limiter, db.NewMemoryRecoveryCodeRepository(users), auditService, mode)
+
+	password := ""
+	if mode == config.LoginPassword {
+		password = testPassword
+	}
+	if err := s.RegisterUser("alice", password, testTelegramID); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	return s, userService
+}
+
+func code(err error) int {
+	if e, ok := err.(*errors.CustomError); ok {
+		return e.Code
+	}
+	return 0
+}
+
+func TestRegisterUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		username   string
+		password   string
+		telegramID int64
+		wantCode   int
+	}{
+		{"с паролем", config.LoginPassword, "bob", "qwerty123", 200, 0},
+		{"без пароля в режиме с паролем", config.LoginPassword, "bob", "", 200, 400},
+		{"короткий пароль", config.LoginPassword, "bob", "abc1", 200, 400},
+		{"пароль без цифр", config.LoginPassword, "bob", "qwertyuiop", 200, 400},
+		{"пароль совпадает с именем", config.LoginPassword, "bobby123", "BOBBY123", 200, 400},
+		{"занятое имя", config.LoginPassword, "alice", "qwerty123", 200, 409},
+		{"занятый Telegram аккаунт", config.LoginPassword, "bob", "qwerty123", testTelegramID, 409},
+		{"без пароля", config.LoginTelegram, "bob", "", 200, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, userService := newTestAuthService(t, tt.mode)
+			err := s.RegisterUser(tt.username, tt.password, tt.telegramID)
+			if got := code(err); got != tt.wantCode {
+				t.Fatalf("RegisterUser() error = %v, want code %d", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			user, err := userService.GetUserByName(tt.username)
+			if err != nil {
+				t.Fatalf("GetUserByName() error = %v", err)
+			}
+			if tt.password == "" && user.Password != "" {
+				t.Error("сохранён пароль, хотя он не задан")
+			}
+			if tt.password != "" && !CheckPasswordHash(tt.password, user.Password) {
+				t.Error("сохранённый хэш не соответствует паролю")
+			}
+		})
+	}
+}
+
+func TestAuthenticateUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		banned     bool
+		username   string
+		password   string
+		telegramID int64
+		wantCode   int
+	}{
+		{"верный пароль", false, "alice", testPassword, testTelegramID, 0},
+		{"неверный пароль", false, "alice", "wrong123", testTelegramID, 401},
+		{"неизвестный пользователь", false, "bob", testPassword, testTelegramID, 401},
+		{"чужой Telegram аккаунт", false, "alice", testPassword, 200, 401},
+		{"заблокированный пользователь", true, "alice", testPassword, testTelegramID, 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, userService := newTestAuthService(t, config.LoginPassword)
+			if tt.banned {
+				alice, _ := userService.GetUserByName("alice")
+				if err := userService.SetUserBanned(alice.ID, true); err != nil {
+					t.Fatalf("SetUserBanned() error = %v", err)
+				}
+			}
+
+			username, err := s.AuthenticateUser(tt.username, tt.password, tt.telegramID)
+			if got := code(err); got != tt.wantCode {
+				t.Fatalf("AuthenticateUser() error = %v, want code %d", err, tt.wantCode)
+			}
+			if err == nil && username != tt.username {
+				t.Errorf("AuthenticateUser() = %q, want %q", username, tt.username)
+			}
+		})
+	}
+}
+
+func TestAuthenticateUserLockout(t *testing.T) {
+	s, _ := newTestAuthService(t, config.LoginPassword)
+
+	for i := 0; i < testMaxAttempts; i++ {
+		if _, err := s.AuthenticateUser("alice", "wrong123", testTelegramID); code(err) != 401 {
+			t.Fatalf("попытка %d: error = %v, want code 401", i+1, err)
+		}
+	}
+	if _, err := s.AuthenticateUser("alice", testPassword, testTelegramID); code(err) != 429 {
+		t.Fatalf("после %d неудач: error = %v, want code 429", testMaxAttempts, err)
+	}
+
+	// Блокировка относится к Telegram аккаунту, а не к имени пользователя.
+	if _, err := s.AuthenticateUser("alice", testPassword, 200); code(err) != 401 {
+		t.Errorf("с другого аккаунта: error = %v, want code 401", err)
+	}
+}
+
+func TestAuthenticateTelegram(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		setPIN     string
+		pin        string
+		telegramID int64
+		wantErr    error
+		wantCode   int
+	}{
+		{"без PIN-кода", config.LoginTelegram, "", "", testTelegramID, nil, 0},
+		{"верный PIN-код", config.LoginTelegram, "1234", "1234", testTelegramID, nil, 0},
+		{"PIN-код не введён", config.LoginTelegram, "1234", "", testTelegramID, ErrPINRequired, 401},
+		{"неверный PIN-код", config.LoginTelegram, "1234", "4321", testTelegramID, nil, 401},
+		{"неизвестный аккаунт", config.LoginTelegram, "", "", 200, nil, 404},
+		{"вход без пароля отключён", config.LoginPassword, "", "", testTelegramID, nil, 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, userService := newTestAuthService(t, tt.mode)
+			if tt.setPIN != "" {
+				alice, _ := userService.GetUserByName("alice")
+				if err := s.SetPIN(alice, tt.setPIN); err != nil {
+					t.Fatalf("SetPIN() error = %v", err)
+				}
+			}
+
+			user, err := s.AuthenticateTelegram(tt.telegramID, tt.pin)
+			if got := code(err); got != tt.wantCode {
+				t.Fatalf("AuthenticateTelegram() error = %v, want code %d", err, tt.wantCode)
+			}
+			if tt.wantErr != nil && err != tt.wantErr {
+				t.Errorf("AuthenticateTelegram() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && user.Username != "alice" {
+				t.Errorf("AuthenticateTelegram() = %s, want alice", user.Username)
+			}
+		})
+	}
+}
+
+func TestValidatePIN(t *testing.T) {
+	tests := []struct {
+		pin     string
+		wantErr bool
+	}{
+		{"1234", false},
+		{"12345678", false},
+		{"123", true},
+		{"123456789", true},
+		{"12a4", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pin, func(t *testing.T) {
+			if err := ValidatePIN(tt.pin); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePIN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		wantCode    int
+	}{
+		{"верный текущий пароль", testPassword, "newsecret456", 0},
+		{"неверный текущий пароль", "wrong123", "newsecret456", 401},
+		{"тот же пароль", testPassword, testPassword, 400},
+		{"слабый новый пароль", testPassword, "short1", 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, userService := newTestAuthService(t, config.LoginPassword)
+			alice, _ := userService.GetUserByName("alice")
+
+			err := s.ChangePassword(alice, tt.oldPassword, tt.newPassword)
+			if got := code(err); got != tt.wantCode {
+				t.Fatalf("ChangePassword() error = %v, want code %d", err, tt.wantCode)
+			}
+
+			want := testPassword
+			if err == nil {
+				want = tt.newPassword
+			}
+			if _, err := s.AuthenticateUser("alice", want, testTelegramID); err != nil {
+				t.Errorf("вход с паролем %q: error = %v", want, err)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name        string
+		wrongCodes  int
+		telegramID  int64
+		newPassword string
+		wantCode    int
+	}{
+		{"верный код", 0, testTelegramID, "newsecret456", 0},
+		{"верный код после неверного", 1, testTelegramID, "newsecret456", 0},
+		{"попытки исчерпаны", maxRecoveryAttempts, testTelegramID, "newsecret456", 400},
+		{"слабый новый пароль", 0, testTelegramID, "short1", 400},
+		{"другой Telegram аккаунт", 0, 200, "newsecret456", 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestAuthService(t, config.LoginPassword)
+			_, recoveryCode, err := s.StartRecovery(testTelegramID)
+			if err != nil {
+				t.Fatalf("StartRecovery() error = %v", err)
+			}
+			if err := ValidateRecoveryCode(recoveryCode); err != nil {
+				t.Fatalf("StartRecovery() выдал код %q: %v", recoveryCode, err)
+			}
+
+			for i := 0; i < tt.wrongCodes; i++ {
+				if _, err := s.Recover(testTelegramID, wrongCode(recoveryCode), tt.newPassword); code(err) != 400 {
+					t.Fatalf("неверный код: error = %v, want code 400", err)
+				}
+			}
+
+			_, err = s.Recover(tt.telegramID, recoveryCode, tt.newPassword)
+			if got := code(err); got != tt.wantCode {
+				t.Fatalf("Recover() error = %v, want code %d", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := s.AuthenticateUser("alice", tt.newPassword, testTelegramID); err != nil {
+				t.Errorf("вход с новым паролем: error = %v", err)
+			}
+			if _, err := s.Recover(testTelegramID, recoveryCode, "another789"); code(err) != 400 {
+				t.Errorf("повторное использование кода: error = %v, want code 400", err)
+			}
+		})
+	}
+}
+
+func TestStartRecoveryResendDelay(t *testing.T) {
+	s, _ := newTestAuthService(t, config.LoginPassword)
+	if _, _, err := s.StartRecovery(testTelegramID); err != nil {
+		t.Fatalf("StartRecovery() error = %v", err)
+	}
+	if _, _, err := s.StartRecovery(testTelegramID); code(err) != 429 {
+		t.Errorf("повторный запрос кода: error = %v, want code 429", err)
+	}
+}
+
+// wrongCode возвращает код той же длины, отличный от code.
+func wrongCode(code string) string {
+	digits := []byte(code)
+	digits[0] = '0' + (digits[0]-'0'+1)%10
+	return string(digits)
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// scopeTelegram - ключ счётчиков неудачных попыток входа по Telegram ID.
+const scopeTelegram = "telegram"
+
+// LoginLimiter считает неудачные попытки входа с каждого Telegram аккаунта.
+// После maxAttempts неудач подряд вход с этого аккаунта блокируется на
+// lockoutBase, и каждая следующая неудача удваивает блокировку, но не больше
+// чем до lockoutMax. Неудачи старше lockoutMax забываются.
+//
+// Счётчика по имени пользователя нет: пароль проверяется, только если
+// сообщение пришло с привязанного к пользователю Telegram аккаунта, поэтому
+// подбирать пароль можно только оттуда. Счётчик по имени позволил бы любому
+// заблокировать вход чужому пользователю.
+type LoginLimiter struct {
+	repo        db.LoginAttemptRepository
+	maxAttempts int
+	lockoutBase time.Duration
+	lockoutMax  time.Duration
+}
+
+func NewLoginLimiter(repo db.LoginAttemptRepository, maxAttempts int, lockoutBase, lockoutMax time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		repo:        repo,
+		maxAttempts: maxAttempts,
+		lockoutBase: lockoutBase,
+		lockoutMax:  lockoutMax,
+	}
+}
+
+// Check возвращает ошибку 429, если вход с этого Telegram ID заблокирован.
+func (l *LoginLimiter) Check(telegramID int64) error {
+	attempt, err := l.repo.GetLoginAttempt(scopeTelegram, telegramKey(telegramID))
+	if err != nil {
+		return nil
+	}
+
+	if wait := time.Until(attempt.LockedUntil); wait > 0 {
+		return errors.New(429, fmt.Sprintf("слишком много неудачных попыток входа, попробуйте через %s", formatWait(wait)))
+	}
+	return nil
+}
+
+// Fail записывает неудачную попытку и возвращает, на сколько заблокирован вход,
+// или 0, если попытки ещё остались.
+func (l *LoginLimiter) Fail(telegramID int64) time.Duration {
+	now := time.Now()
+	key := telegramKey(telegramID)
+	failures, err := l.repo.AddLoginFailure(scopeTelegram, key, now, now.Add(-l.lockoutMax))
+	if err != nil {
+		logging.Logger.Printf("Ошибка в записи попытки входа: %v", err)
+		return 0
+	}
+	if failures < l.maxAttempts {
+		return 0
+	}
+
+	lockout := l.lockout(failures - l.maxAttempts)
+	if err := l.repo.LockLogin(scopeTelegram, key, now.Add(lockout)); err != nil {
+		logging.Logger.Printf("Ошибка в блокировке входа: %v", err)
+		return 0
+	}
+	return lockout
+}
+
+// Reset сбрасывает счётчик и блокировку Telegram ID. Возвращает false,
+// если сбрасывать было нечего.
+func (l *LoginLimiter) Reset(telegramID int64) (bool, error) {
+	deleted, err := l.repo.DeleteLoginAttempts(scopeTelegram, telegramKey(telegramID))
+	return deleted > 0, err
+}
+
+// RunCleanup периодически удаляет счётчики, неудачи в которых уже забыты,
+// пока не будет отменён ctx.
+func (l *LoginLimiter) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Блокировка не длиннее lockoutMax, поэтому к этому времени она уже снята.
+			deleted, err := l.repo.DeleteStaleLoginAttempts(time.Now().Add(-l.lockoutMax))
+			if err != nil {
+				logging.Logger.Printf("Ошибка в очистке попыток входа: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				logging.Logger.Printf("Удалено устаревших счётчиков попыток входа: %d", deleted)
+			}
+		}
+	}
+}
+
+// lockout возвращает длительность блокировки после extra неудач сверх maxAttempts.
+func (l *LoginLimiter) lockout(extra int) time.Duration {
+	lockout := l.lockoutBase
+	for i := 0; i < extra && lockout < l.lockoutMax; i++ {
+		lockout *= 2
+	}
+	if lockout > l.lockoutMax {
+		lockout = l.lockoutMax
+	}
+	return lockout
+}
+
+func telegramKey(telegramID int64) string {
+	return strconv.FormatInt(telegramID, 10)
+}
+
+// formatWait округляет оставшееся время блокировки вверх до минуты.
+func formatWait(d time.Duration) string {
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%d мин.", minutes)
+	}
+	return fmt.Sprintf("%d ч. %d мин.", minutes/60, minutes%60)
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordLength - bcrypt учитывает только первые 72 байта пароля.
+	maxPasswordLength = 72
+
+	recoveryCodeDigits = 6
+	// RecoveryCodeTTL - сколько действует код восстановления.
+	RecoveryCodeTTL = 10 * time.Minute
+	// recoveryResendDelay - как часто можно запрашивать новый код.
+	recoveryResendDelay = time.Minute
+	// maxRecoveryAttempts - после стольких неверных попыток код перестаёт действовать.
+	maxRecoveryAttempts = 5
+)
+
+// ValidatePassword проверяет пароль на соответствие политике: от 8 до 72 байт,
+// хотя бы одна буква и одна цифра, пароль не совпадает с именем пользователя.
+func ValidatePassword(username, password string) error {
+	if len(password) < minPasswordLength {
+		return errors.New(400, fmt.Sprintf("пароль должен быть не короче %d символов", minPasswordLength))
+	}
+	if len(password) > maxPasswordLength {
+		return errors.New(400, fmt.Sprintf("пароль должен быть не длиннее %d байт", maxPasswordLength))
+	}
+
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		return errors.New(400, "пароль должен содержать хотя бы одну букву и одну цифру")
+	}
+
+	if strings.EqualFold(password, username) {
+		return errors.New(400, "пароль не должен совпадать с именем пользователя")
+	}
+	return nil
+}
+
+// ValidateRecoveryCode проверяет, что код восстановления состоит из нужного числа цифр.
+func ValidateRecoveryCode(code string) error {
+	if len(code) != recoveryCodeDigits || strings.Trim(code, "0123456789") != "" {
+		return errors.New(400, fmt.Sprintf("код восстановления состоит из %d цифр", recoveryCodeDigits))
+	}
+	return nil
+}
+
+// ChangePassword меняет пароль пользователя, если oldPassword верный. Неверный
+// старый пароль считается неудачной попыткой входа.
+func (s *AuthService) ChangePassword(user *models.User, oldPassword, newPassword string) error {
+	if err := s.limiter.Check(user.TelegramID); err != nil {
+		return err
+	}
+	if !CheckPasswordHash(oldPassword, user.Password) {
+		s.fail(user.Username, user.TelegramID, user.ID, "неверный пароль при смене пароля")
+		return errors.New(401, "неверный текущий пароль")
+	}
+	if oldPassword == newPassword {
+		return errors.New(400, "новый пароль совпадает с текущим")
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return err
+	}
+	s.audit.Record(audit.EventPasswordChanged, user.ID, user.ID, "")
+	return nil
+}
+
+// StartRecovery выдаёт одноразовый код восстановления пароля пользователю,
+// привязанному к telegramID. Код нужно передать пользователю в его Telegram
+// аккаунт: ввести его можно только оттуда же.
+func (s *AuthService) StartRecovery(telegramID int64) (*models.User, string, error) {
+	user, err := s.userService.GetUserByTgID(telegramID)
+	if err != nil {
+		return nil, "", errors.New(404, "к этому Telegram аккаунту не привязан пользователь")
+	}
+	if user.Banned {
+		return nil, "", errors.New(403, "аккаунт заблокирован")
+	}
+
+	now := time.Now()
+	if previous, err := s.recoveryCodes.GetRecoveryCode(user.ID); err == nil && now.Sub(previous.CreatedAt) < recoveryResendDelay {
+		return nil, "", errors.New(429, "код уже отправлен, новый можно запросить через минуту")
+	}
+
+	code, err := randomDigits(recoveryCodeDigits)
+	if err != nil {
+		return nil, "", errors.New(500, fmt.Sprintf("не удалось создать код: %v", err))
+	}
+	hash, err := HashPassword(code)
+	if err != nil {
+		return nil, "", errors.New(500, fmt.Sprintf("не удалось хэшировать код: %v", err))
+	}
+
+	err = s.recoveryCodes.SaveRecoveryCode(&models.RecoveryCode{
+		UserID:    user.ID,
+		CodeHash:  hash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(RecoveryCodeTTL),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.audit.Record(audit.EventRecoveryRequested, user.ID, user.ID, "")
+	return user, code, nil
+}
+
+// Recover задаёт новый пароль по коду восстановления. Код действует
+// RecoveryCodeTTL и допускает maxRecoveryAttempts неверных попыток.
+func (s *AuthService) Recover(telegramID int64, code, newPassword string) (*models.User, error) {
+	user, err := s.userService.GetUserByTgID(telegramID)
+	if err != nil {
+		return nil, errors.New(404, "к этому Telegram аккаунту не привязан пользователь")
+	}
+
+	recovery, err := s.recoveryCodes.GetRecoveryCode(user.ID)
+	if err != nil {
+		return nil, errors.New(400, "код восстановления не запрошен, используйте /recover")
+	}
+	if time.Now().After(recovery.ExpiresAt) || recovery.Attempts >= maxRecoveryAttempts {
+		s.deleteRecoveryCode(user.ID)
+		return nil, errors.New(400, "код восстановления больше не действует, запросите новый командой /recover")
+	}
+
+	if !CheckPasswordHash(code, recovery.CodeHash) {
+		attempts, err := s.recoveryCodes.AddRecoveryAttempt(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.audit.Record(audit.EventRecoveryFailed, user.ID, user.ID, fmt.Sprintf("попытка %d из %d", attempts, maxRecoveryAttempts))
+		if attempts >= maxRecoveryAttempts {
+			s.deleteRecoveryCode(user.ID)
+			return nil, errors.New(400, "неверный код, попытки исчерпаны, запросите новый код командой /recover")
+		}
+		return nil, errors.New(400, "неверный код восстановления")
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+	s.deleteRecoveryCode(user.ID)
+	if _, err := s.limiter.Reset(telegramID); err != nil {
+		logging.Logger.Printf("Ошибка в сбросе попыток входа %s: %v", user.Username, err)
+	}
+
+	s.audit.Record(audit.EventPasswordRecovered, user.ID, user.ID, "")
+	return user, nil
+}
+
+func (s *AuthService) setPassword(user *models.User, password string) error {
+	if err := ValidatePassword(user.Username, password); err != nil {
+		return err
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return errors.New(500, fmt.Sprintf("не удалось хэшировать пароль: %v", err))
+	}
+	return s.userService.SetUserPassword(user.ID, hash)
+}
+
+func (s *AuthService) deleteRecoveryCode(userID int64) {
+	if err := s.recoveryCodes.DeleteRecoveryCode(userID); err != nil {
+		logging.Logger.Printf("Ошибка в удалении кода восстановления пользователя %d: %v", userID, err)
+	}
+}
+
+func randomDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
+}
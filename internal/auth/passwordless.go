@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"fmt"
+)
+
+const (
+	minPINLength = 4
+	maxPINLength = 8
+)
+
+// ErrPINRequired возвращается AuthenticateTelegram, если у пользователя задан
+// PIN-код, а в попытке входа его нет.
+var ErrPINRequired = errors.New(401, "введите PIN-код")
+
+// AuthenticateTelegram выполняет вход без пароля: пользователя определяет
+// telegramID, который Telegram сообщает вместе с сообщением и который нельзя
+// подделать. Если пользователь задал PIN-код, он тоже проверяется, и неверные
+// PIN-коды считаются неудачными попытками входа.
+func (s *AuthService) AuthenticateTelegram(telegramID int64, pin string) (*models.User, error) {
+	if !s.Passwordless() {
+		return nil, errors.New(403, "вход без пароля отключён")
+	}
+
+	user, err := s.userService.GetUserByTgID(telegramID)
+	if err != nil {
+		return nil, errors.New(404, "к этому Telegram аккаунту не привязан пользователь, зарегистрируйтесь командой /register")
+	}
+
+	if err := s.limiter.Check(telegramID); err != nil {
+		s.audit.Record(audit.EventLoginFailed, 0, user.ID, "вход заблокирован")
+		return nil, err
+	}
+
+	if user.PIN != "" {
+		if pin == "" {
+			return nil, ErrPINRequired
+		}
+		if !CheckPasswordHash(pin, user.PIN) {
+			s.fail(user.Username, telegramID, user.ID, "неверный PIN-код")
+			return nil, errors.New(401, "неверный PIN-код")
+		}
+	}
+
+	if user.Banned {
+		s.audit.Record(audit.EventLoginFailed, user.ID, user.ID, "аккаунт заблокирован")
+		return nil, errors.New(403, "аккаунт заблокирован")
+	}
+
+	if _, err := s.limiter.Reset(telegramID); err != nil {
+		logging.Logger.Printf("Ошибка в сбросе попыток входа %s: %v", user.Username, err)
+	}
+	s.audit.Record(audit.EventLogin, user.ID, user.ID, "по Telegram аккаунту")
+
+	return user, nil
+}
+
+// SetPIN задаёт пользователю PIN-код для входа без пароля. Пустой pin удаляет PIN-код.
+func (s *AuthService) SetPIN(user *models.User, pin string) error {
+	var hash string
+	if pin != "" {
+		if err := ValidatePIN(pin); err != nil {
+			return err
+		}
+		h, err := HashPassword(pin)
+		if err != nil {
+			return errors.New(500, fmt.Sprintf("не удалось хэшировать PIN-код: %v", err))
+		}
+		hash = h
+	}
+
+	if err := s.userService.SetUserPIN(user.ID, hash); err != nil {
+		return err
+	}
+
+	details := "задан"
+	if pin == "" {
+		details = "удалён"
+	}
+	s.audit.Record(audit.EventPINChanged, user.ID, user.ID, details)
+	return nil
+}
+
+// ValidatePIN проверяет, что PIN-код состоит из 4-8 цифр.
+func ValidatePIN(pin string) error {
+	if len(pin) < minPINLength || len(pin) > maxPINLength {
+		return errors.New(400, fmt.Sprintf("PIN-код должен состоять из %d-%d цифр", minPINLength, maxPINLength))
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return errors.New(400, fmt.Sprintf("PIN-код должен состоять из %d-%d цифр", minPINLength, maxPINLength))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package birthday
+
+import (
+	"BirthdayGreetings/internal/errors"
+	"fmt"
+	"time"
+)
+
+// LeapPolicy определяет, когда поздравлять родившихся 29 февраля в невисокосный год.
+type LeapPolicy int
+
+const (
+	// LeapFeb28 - поздравлять 28 февраля.
+	LeapFeb28 LeapPolicy = iota
+	// LeapMar1 - поздравлять 1 марта.
+	LeapMar1
+)
+
+func ParseLeapPolicy(value string) (LeapPolicy, error) {
+	switch value {
+	case "", "feb28":
+		return LeapFeb28, nil
+	case "mar1":
+		return LeapMar1, nil
+	default:
+		return LeapFeb28, errors.New(400, fmt.Sprintf("неизвестная политика для 29 февраля: %s (ожидается feb28 или mar1)", value))
+	}
+}
+
+func (p LeapPolicy) String() string {
+	if p == LeapMar1 {
+		return "mar1"
+	}
+	return "feb28"
+}
+
+// UnmarshalText позволяет читать политику из файлов настроек.
+func (p *LeapPolicy) UnmarshalText(text []byte) error {
+	policy, err := ParseLeapPolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+// Matcher сопоставляет даты рождения с календарными датами с учётом политики для 29 февраля.
+type Matcher struct {
+	policy LeapPolicy
+}
+
+func NewMatcher(policy LeapPolicy) *Matcher {
+	return &Matcher{policy: policy}
+}
+
+func (m *Matcher) Policy() LeapPolicy {
+	return m.policy
+}
+
+// IsSet сообщает, указана ли дата рождения. Незаполненная дата хранится как 0001-01-01.
+func IsSet(birthday time.Time) bool {
+	return birthday.Year() > 1
+}
+
+// Occurrence возвращает дату, в которую день рождения празднуется в году year.
+func (m *Matcher) Occurrence(birthday time.Time, year int) time.Time {
+	month, day := birthday.Month(), birthday.Day()
+	if month == time.February && day == 29 && !isLeap(year) {
+		if m.policy == LeapMar1 {
+			month, day = time.March, 1
+		} else {
+			day = 28
+		}
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Next возвращает ближайшую дату празднования, начиная с today включительно.
+func (m *Matcher) Next(birthday, today time.Time) time.Time {
+	from := dateOf(today)
+	next := m.Occurrence(birthday, from.Year())
+	if next.Before(from) {
+		next = m.Occurrence(birthday, from.Year()+1)
+	}
+	return next
+}
+
+// DaysUntil возвращает количество дней от today до ближайшего дня рождения.
+func (m *Matcher) DaysUntil(birthday, today time.Time) int {
+	return int(m.Next(birthday, today).Sub(dateOf(today)).Hours() / 24)
+}
+
+// IsToday сообщает, празднуется ли день рождения в дату today.
+func (m *Matcher) IsToday(birthday, today time.Time) bool {
+	return m.DaysUntil(birthday, today) == 0
+}
+
+// Keys возвращает значения даты рождения в формате MM-DD, которые празднуются
+// в дату date. Используется для выборки именинников из базы данных.
+func (m *Matcher) Keys(date time.Time) []string {
+	keys := []string{date.Format("01-02")}
+	if isLeap(date.Year()) {
+		return keys
+	}
+
+	switch {
+	case m.policy == LeapFeb28 && date.Month() == time.February && date.Day() == 28:
+		keys = append(keys, "02-29")
+	case m.policy == LeapMar1 && date.Month() == time.March && date.Day() == 1:
+		keys = append(keys, "02-29")
+	}
+	return keys
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
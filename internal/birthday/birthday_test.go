@@ -0,0 +1,125 @@
+package birthday
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOccurrence(t *testing.T) {
+	leapBirthday := date(2000, time.February, 29)
+	tests := []struct {
+		name     string
+		policy   LeapPolicy
+		birthday time.Time
+		year     int
+		want     time.Time
+	}{
+		{"29 февраля в високосный год, feb28", LeapFeb28, leapBirthday, 2024, date(2024, time.February, 29)},
+		{"29 февраля в високосный год, mar1", LeapMar1, leapBirthday, 2024, date(2024, time.February, 29)},
+		{"29 февраля в невисокосный год, feb28", LeapFeb28, leapBirthday, 2025, date(2025, time.February, 28)},
+		{"29 февраля в невисокосный год, mar1", LeapMar1, leapBirthday, 2025, date(2025, time.March, 1)},
+		{"1900 не високосный", LeapFeb28, leapBirthday, 1900, date(1900, time.February, 28)},
+		{"обычная дата", LeapMar1, date(1990, time.July, 15), 2025, date(2025, time.July, 15)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMatcher(tt.policy).Occurrence(tt.birthday, tt.year)
+			if !got.Equal(tt.want) {
+				t.Errorf("Occurrence() = %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestNextAndDaysUntil(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   LeapPolicy
+		birthday time.Time
+		today    time.Time
+		wantNext time.Time
+		wantDays int
+	}{
+		{"сегодня", LeapFeb28, date(1990, time.December, 31), date(2024, time.December, 31), date(2024, time.December, 31), 0},
+		{"через новый год", LeapFeb28, date(1990, time.January, 1), date(2024, time.December, 31), date(2025, time.January, 1), 1},
+		{"уже прошёл в этом году", LeapFeb28, date(1990, time.December, 30), date(2024, time.December, 31), date(2025, time.December, 30), 364},
+		{"время дня не учитывается", LeapFeb28, date(1990, time.January, 1), time.Date(2024, time.December, 31, 23, 59, 0, 0, time.UTC), date(2025, time.January, 1), 1},
+		{"29 февраля из високосного года, feb28", LeapFeb28, date(2000, time.February, 29), date(2024, time.March, 1), date(2025, time.February, 28), 364},
+		{"29 февраля из високосного года, mar1", LeapMar1, date(2000, time.February, 29), date(2024, time.March, 1), date(2025, time.March, 1), 365},
+		{"29 февраля в невисокосный год, feb28", LeapFeb28, date(2000, time.February, 29), date(2025, time.February, 28), date(2025, time.February, 28), 0},
+		{"29 февраля в невисокосный год, mar1", LeapMar1, date(2000, time.February, 29), date(2025, time.February, 28), date(2025, time.March, 1), 1},
+		{"29 февраля в високосный год", LeapMar1, date(2000, time.February, 29), date(2024, time.February, 28), date(2024, time.February, 29), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMatcher(tt.policy)
+			if got := m.Next(tt.birthday, tt.today); !got.Equal(tt.wantNext) {
+				t.Errorf("Next() = %s, want %s", got.Format("2006-01-02"), tt.wantNext.Format("2006-01-02"))
+			}
+			if got := m.DaysUntil(tt.birthday, tt.today); got != tt.wantDays {
+				t.Errorf("DaysUntil() = %d, want %d", got, tt.wantDays)
+			}
+			if got := m.IsToday(tt.birthday, tt.today); got != (tt.wantDays == 0) {
+				t.Errorf("IsToday() = %v, want %v", got, tt.wantDays == 0)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy LeapPolicy
+		date   time.Time
+		want   []string
+	}{
+		{"28 февраля невисокосного года, feb28", LeapFeb28, date(2025, time.February, 28), []string{"02-28", "02-29"}},
+		{"28 февраля невисокосного года, mar1", LeapMar1, date(2025, time.February, 28), []string{"02-28"}},
+		{"1 марта невисокосного года, feb28", LeapFeb28, date(2025, time.March, 1), []string{"03-01"}},
+		{"1 марта невисокосного года, mar1", LeapMar1, date(2025, time.March, 1), []string{"03-01", "02-29"}},
+		{"28 февраля високосного года", LeapFeb28, date(2024, time.February, 28), []string{"02-28"}},
+		{"29 февраля високосного года", LeapMar1, date(2024, time.February, 29), []string{"02-29"}},
+		{"1 марта високосного года", LeapMar1, date(2024, time.March, 1), []string{"03-01"}},
+		{"обычная дата", LeapFeb28, date(2025, time.January, 1), []string{"01-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewMatcher(tt.policy).Keys(tt.date); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLeapPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    LeapPolicy
+		wantErr bool
+	}{
+		{"", LeapFeb28, false},
+		{"feb28", LeapFeb28, false},
+		{"mar1", LeapMar1, false},
+		{"march", LeapFeb28, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseLeapPolicy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLeapPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLeapPolicy() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"BirthdayGreetings/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackDeleteAccount = "delacc"
+	callbackKeepAccount   = "keepacc"
+)
+
+// deleteConfirmTimeout - сколько действует кнопка подтверждения удаления аккаунта.
+const deleteConfirmTimeout = 5 * time.Minute
+
+// handleExportMyDataCommand отправляет пользователю JSON-файл со всеми его данными.
+// Файл всегда отправляется в личные сообщения, даже если команда пришла из группы.
+func (s *BotService) handleExportMyDataCommand(message *tgbotapi.Message) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error()))
+		return
+	}
+
+	export, err := s.account.Export(user)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось выгрузить данные: "+err.Error()))
+		return
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось выгрузить данные: "+err.Error()))
+		return
+	}
+
+	document := tgbotapi.NewDocument(user.TelegramID, tgbotapi.FileBytes{
+		Name:  "birthdaygreetings-" + user.Username + ".json",
+		Bytes: data,
+	})
+	document.Caption = "Все данные, которые бот хранит о вас."
+	if _, err := s.bot.Send(document); err != nil {
+		logging.Logger.Printf("Ошибка в отправлении выгрузки пользователю %s: %v", user.Username, err)
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось отправить файл. Напишите боту в личные сообщения и повторите команду."))
+		return
+	}
+	if message.Chat.ID != user.TelegramID {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Файл с вашими данными отправлен вам в личные сообщения."))
+	}
+}
+
+// handleDeleteAccountCommand просит подтвердить удаление аккаунта кнопкой.
+func (s *BotService) handleDeleteAccountCommand(message *tgbotapi.Message) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error()))
+		return
+	}
+
+	userArg := strconv.FormatInt(user.ID, 10)
+	timeArg := strconv.FormatInt(time.Now().Unix(), 10)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить навсегда", callbackData(callbackDeleteAccount, userArg, timeArg)),
+		tgbotapi.NewInlineKeyboardButtonData("Отмена", callbackData(callbackKeepAccount)),
+	))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Удалить аккаунт "+user.Username+"? Будут удалены профиль, подписки, подписки на вас, напоминания, сессии и журнал уведомлений. Восстановить их будет нельзя.\n"+
+		"Сохранить копию данных можно командой /exportmydata.")
+	msg.ReplyMarkup = keyboard
+	s.bot.Send(msg)
+}
+
+func (s *BotService) handleDeleteAccountCallback(query *tgbotapi.CallbackQuery, args []string) {
+	if len(args) != 2 {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	requested, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+
+	user, err := s.userService.GetUserByTgID(query.From.ID)
+	if err != nil {
+		s.answerCallback(query, "Ошибка в поиске пользователя: "+err.Error())
+		return
+	}
+	if user.ID != userID {
+		s.answerCallback(query, "Эта кнопка предназначена другому пользователю.")
+		return
+	}
+	if time.Since(time.Unix(requested, 0)) > deleteConfirmTimeout {
+		s.answerCallback(query, "Подтверждение устарело, повторите /deleteaccount.")
+		s.closeConfirmation(query, "Удаление аккаунта не подтверждено вовремя.")
+		return
+	}
+
+	if err := s.account.Delete(user); err != nil {
+		s.answerCallback(query, "Не удалось удалить аккаунт: "+err.Error())
+		return
+	}
+	s.dialogs.end(query.Message.Chat.ID)
+
+	s.answerCallback(query, "")
+	s.closeConfirmation(query, "Аккаунт "+user.Username+" удалён вместе со всеми данными. Зарегистрироваться заново можно командой /register.")
+}
+
+func (s *BotService) handleKeepAccountCallback(query *tgbotapi.CallbackQuery, _ []string) {
+	s.answerCallback(query, "")
+	s.closeConfirmation(query, "Удаление аккаунта отменено.")
+}
+
+// closeConfirmation заменяет сообщение с кнопками подтверждения на text.
+func (s *BotService) closeConfirmation(query *tgbotapi.CallbackQuery, text string) {
+	s.bot.Send(tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text))
+}
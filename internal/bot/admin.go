@@ -0,0 +1,326 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// auditDefaultLimit - сколько событий показывает /audit без фильтра limit.
+const auditDefaultLimit = 20
+
+// registerAdminCommands описывает команды, доступные только администраторам и владельцу.
+func (s *BotService) registerAdminCommands() {
+	s.commands.add(&command{name: "/users", description: "Все пользователи с ролями и блокировками.", role: models.RoleAdmin,
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleAdminUsersCommand(m) }})
+	s.commands.add(&command{name: "/ban", usage: "<username>", description: "Заблокировать пользователя.", role: models.RoleAdmin,
+		handler: s.dialogCommand("/ban")})
+	s.commands.add(&command{name: "/unban", usage: "<username>", description: "Разблокировать пользователя.", role: models.RoleAdmin,
+		handler: s.dialogCommand("/unban")})
+	s.commands.add(&command{name: "/unlock", usage: "<username>", description: "Снять блокировку входа после неудачных попыток.", role: models.RoleAdmin,
+		handler: s.dialogCommand("/unlock")})
+	s.commands.add(&command{name: "/resetpassword", usage: "<username>", description: "Выдать пользователю временный пароль.", role: models.RoleAdmin,
+		handler: s.dialogCommand("/resetpassword")})
+	s.commands.add(&command{name: "/editbirthday", usage: "<username> <YYYY-MM-DD>", description: "Изменить дату рождения пользователя.", role: models.RoleAdmin,
+		handler: s.dialogCommand("/editbirthday")})
+	s.commands.add(&command{name: "/runnotifications", description: "Запустить ежедневную рассылку сейчас.", role: models.RoleAdmin,
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleRunNotificationsCommand(m) }})
+	s.commands.add(&command{name: "/audit", usage: "[user=<username>] [type=<тип>] [limit=<N>]", description: "Журнал аудита, начиная с новых событий.", role: models.RoleAdmin,
+		handler: s.handleAuditCommand})
+	s.commands.add(&command{name: "/promote", usage: "<username>", description: "Назначить пользователя администратором.", role: models.RoleOwner,
+		handler: s.dialogCommand("/promote")})
+	s.commands.add(&command{name: "/demote", usage: "<username>", description: "Снять с пользователя права администратора.", role: models.RoleOwner,
+		handler: s.dialogCommand("/demote")})
+}
+
+func (s *BotService) registerAdminDialogs() {
+	target := dialogField{key: "username", prompt: "Введите имя пользователя.", validate: validateUsername}
+
+	s.dialogs.register("/ban", dialogFlow{
+		fields: []dialogField{target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleBanCommandArgs(message, values["username"], true)
+		},
+	})
+	s.dialogs.register("/unban", dialogFlow{
+		fields: []dialogField{target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleBanCommandArgs(message, values["username"], false)
+		},
+	})
+	s.dialogs.register("/unlock", dialogFlow{
+		fields: []dialogField{target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleUnlockCommandArgs(message, values["username"])
+		},
+	})
+	s.dialogs.register("/resetpassword", dialogFlow{
+		fields: []dialogField{target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleResetPasswordCommandArgs(message, values["username"])
+		},
+	})
+	s.dialogs.register("/editbirthday", dialogFlow{
+		fields: []dialogField{
+			target,
+			{key: "birthday", prompt: "Введите дату рождения в формате YYYY-MM-DD.", validate: validateDate},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleEditBirthdayCommandArgs(message, values["username"], values["birthday"])
+		},
+	})
+	s.dialogs.register("/promote", dialogFlow{
+		fields: []dialogField{target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleRoleCommandArgs(message, values["username"], true)
+		},
+	})
+	s.dialogs.register("/demote", dialogFlow{
+		fields: []dialogField{target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleRoleCommandArgs(message, values["username"], false)
+		},
+	})
+}
+
+// dialogCommand возвращает обработчик команды, которая собирает аргументы диалогом name.
+func (s *BotService) dialogCommand(name string) func(message *tgbotapi.Message, args []string) {
+	return func(message *tgbotapi.Message, args []string) {
+		s.startDialog(message, name, args, "")
+	}
+}
+
+// currentRole возвращает роль пользователя, написавшего сообщение.
+// Для незарегистрированных пользователей возвращается models.RoleUser.
+func (s *BotService) currentRole(telegramID int64) models.Role {
+	user, err := s.userService.GetUserByTgID(telegramID)
+	if err != nil {
+		return models.RoleUser
+	}
+	return s.admin.RoleOf(user)
+}
+
+// commandUser возвращает пользователя, выполняющего команду,
+// или сообщает об ошибке и возвращает nil.
+func (s *BotService) commandUser(message *tgbotapi.Message) *models.User {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error()))
+		return nil
+	}
+	return user
+}
+
+func (s *BotService) handleAdminUsersCommand(message *tgbotapi.Message) {
+	users, err := s.admin.ListUsers()
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователей: "+err.Error()))
+		return
+	}
+	if len(users) == 0 {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Пользователей нет."))
+		return
+	}
+
+	var lines []string
+	for _, user := range users {
+		line := fmt.Sprintf("%s [%s]", user.Username, user.Role)
+		if birthday.IsSet(user.Birthday) {
+			line += " " + user.Birthday.Format("2006-01-02")
+		}
+		if user.Banned {
+			line += " - заблокирован"
+		}
+		lines = append(lines, line)
+	}
+	if err := s.sendLines(message.Chat.ID, "Пользователи:", lines); err != nil {
+		logging.Logger.Printf("Ошибка в отправлении списка пользователей: %v", err)
+	}
+}
+
+func (s *BotService) handleBanCommandArgs(message *tgbotapi.Message, username string, ban bool) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	var err error
+	text := "Пользователь " + username + " заблокирован, его сессии завершены."
+	if ban {
+		err = s.admin.Ban(actor, username)
+	} else {
+		err = s.admin.Unban(actor, username)
+		text = "Пользователь " + username + " разблокирован."
+	}
+	if err != nil {
+		text = "Ошибка: " + err.Error()
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+func (s *BotService) handleUnlockCommandArgs(message *tgbotapi.Message, username string) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	text := "Вход для пользователя " + username + " разблокирован."
+	if err := s.admin.Unlock(actor, username); err != nil {
+		text = "Ошибка: " + err.Error()
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+// handleResetPasswordCommandArgs отправляет временный пароль самому пользователю.
+// Администратор видит пароль, только если доставить его пользователю не удалось.
+func (s *BotService) handleResetPasswordCommandArgs(message *tgbotapi.Message, username string) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	target, password, err := s.admin.ResetPassword(actor, username)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+
+	notice := "Администратор сбросил ваш пароль. Временный пароль: " + password + "\nВойдите с ним командой /login."
+	if err := s.SendMessage(target.TelegramID, notice); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Пароль сброшен, но отправить его пользователю не удалось. Передайте его сами: "+password))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Пароль пользователя "+username+" сброшен, временный пароль отправлен ему в личные сообщения."))
+}
+
+func (s *BotService) handleEditBirthdayCommandArgs(message *tgbotapi.Message, username, date string) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	if err := s.admin.SetBirthday(actor, username, date); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Дата рождения пользователя "+username+" изменена на "+date+"."))
+}
+
+func (s *BotService) handleRunNotificationsCommand(message *tgbotapi.Message) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Рассылка запущена."))
+	if err := s.admin.RunNotifications(actor); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка рассылки: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Рассылка выполнена."))
+}
+
+// handleAuditCommand показывает журнал аудита. Фильтры задаются как ключ=значение:
+// user - события, где пользователь автор или цель, type - тип события
+// или категория (auth, user, subscription, admin), limit - количество событий.
+func (s *BotService) handleAuditCommand(message *tgbotapi.Message, args []string) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	filter := models.AuditFilter{Limit: auditDefaultLimit}
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			s.sendUsage(message, "/audit", nil)
+			return
+		}
+
+		switch key {
+		case "user":
+			user, err := s.userService.GetUserByName(value)
+			if err != nil {
+				s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Пользователь "+value+" не найден."))
+				return
+			}
+			filter.UserID = user.ID
+		case "type":
+			filter.Type = value
+		case "limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				s.sendUsage(message, "/audit", errors.New(400, "limit должен быть положительным числом"))
+				return
+			}
+			filter.Limit = limit
+		default:
+			s.sendUsage(message, "/audit", errors.New(400, "неизвестный фильтр "+key))
+			return
+		}
+	}
+
+	events, err := s.admin.Events(actor, filter)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	if len(events) == 0 {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Событий не найдено."))
+		return
+	}
+
+	lines := make([]string, 0, len(events))
+	for _, e := range events {
+		line := e.CreatedAt.Format("2006-01-02 15:04") + " " + e.Type + " " + auditName(e.ActorID, e.ActorName)
+		if e.UserID != 0 && e.UserID != e.ActorID {
+			line += " -> " + auditName(e.UserID, e.UserName)
+		}
+		if e.Details != "" {
+			line += ": " + e.Details
+		}
+		lines = append(lines, line)
+	}
+	if err := s.sendLines(message.Chat.ID, "Журнал аудита:", lines); err != nil {
+		logging.Logger.Printf("Ошибка в отправлении журнала аудита: %v", err)
+	}
+}
+
+// auditName возвращает имя участника события. Удалённые пользователи показываются по ID.
+func auditName(id int64, name string) string {
+	switch {
+	case name != "":
+		return name
+	case id != 0:
+		return fmt.Sprintf("#%d", id)
+	default:
+		return "-"
+	}
+}
+
+func (s *BotService) handleRoleCommandArgs(message *tgbotapi.Message, username string, promote bool) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	var err error
+	text := "Пользователь " + username + " назначен администратором."
+	if promote {
+		err = s.admin.Promote(actor, username)
+	} else {
+		err = s.admin.Demote(actor, username)
+		text = "Пользователь " + username + " больше не администратор."
+	}
+	if err != nil {
+		text = "Ошибка: " + err.Error()
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+}
@@ -3,189 +3,403 @@ package bot
 import (
 	"context"
 	"fmt"
-	"os"
+	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
+	"BirthdayGreetings/internal/account"
+	"BirthdayGreetings/internal/admin"
 	"BirthdayGreetings/internal/auth"
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/collection"
+	"BirthdayGreetings/internal/config"
+	"BirthdayGreetings/internal/db"
 	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/reminder"
 	"BirthdayGreetings/internal/service"
+	"BirthdayGreetings/internal/session"
 	"BirthdayGreetings/internal/subscription"
+	"BirthdayGreetings/internal/team"
 	"BirthdayGreetings/internal/telegram"
+	"BirthdayGreetings/internal/wish"
+	"BirthdayGreetings/internal/wishlist"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// TelegramAPI - методы Telegram Bot API, которыми пользуется бот. Его
+// реализует *tgbotapi.BotAPI, в тестах вместо него подставляется клиент,
+// который не ходит в сеть.
+type TelegramAPI interface {
+	GetMe() (tgbotapi.User, error)
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
+	HandleUpdate(r *http.Request) (*tgbotapi.Update, error)
+}
+
 type BotService struct {
-	bot            *tgbotapi.BotAPI
+	bot            TelegramAPI
+	self           tgbotapi.User
 	authService    *auth.AuthService
 	userService    *service.UserService
 	subService     *subscription.SubscriptionService
+	reminders      *reminder.ReminderService
+	matcher        *birthday.Matcher
+	sessions       *session.SessionService
+	admin          *admin.AdminService
+	account        *account.AccountService
+	teams          *team.TeamService
+	collections    *collection.CollectionService
+	wishes         *wish.WishService
+	wishlists      *wishlist.WishlistService
+	wishDeliverer  WishDeliverer
 	telegramClient *telegram.Client
-	pendingCmd     map[int64]string
-	sessionStore   map[int64]bool
-	mu             sync.Mutex
-	adminID        int64
+	dialogs        *dialogManager
+	commands       *commandRegistry
+	callbacks      map[string]callbackHandler
+	config         config.Bot
+	// stop получает контекст Stop: его срок ограничивает завершение приёма обновлений.
+	stop     chan context.Context
+	stopOnce sync.Once
+	done     chan struct{}
+	adminID  int64
 }
 
-func NewBotService(authService *auth.AuthService, userService *service.UserService, subService *subscription.SubscriptionService, telegramClient *telegram.Client) (*BotService, error) {
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	bot, err := tgbotapi.NewBotAPI(botToken)
+func NewBotService(api TelegramAPI, authService *auth.AuthService, userService *service.UserService, subService *subscription.SubscriptionService, reminders *reminder.ReminderService, matcher *birthday.Matcher, sessions *session.SessionService, adminService *admin.AdminService, accountService *account.AccountService, teamService *team.TeamService, collectionService *collection.CollectionService, wishService *wish.WishService, wishlistService *wishlist.WishlistService, dialogRepo db.DialogRepository, cfg config.Bot, telegramClient *telegram.Client) (*BotService, error) {
+	self, err := api.GetMe()
 	if err != nil {
 		return nil, err
 	}
 
-	adminID, err := strconv.Atoi(os.Getenv("ADMIN_ID"))
-	if err != nil {
-		logging.Logger.Fatalf("Ошибка парсинга ADMIN_ID: %v", err)
-	}
-
-	return &BotService{
-		bot:            bot,
+	s := &BotService{
+		bot:            api,
+		self:           self,
 		authService:    authService,
 		userService:    userService,
 		subService:     subService,
+		reminders:      reminders,
+		matcher:        matcher,
+		sessions:       sessions,
+		admin:          adminService,
+		account:        accountService,
+		teams:          teamService,
+		collections:    collectionService,
+		wishes:         wishService,
+		wishlists:      wishlistService,
 		telegramClient: telegramClient,
-		pendingCmd:     make(map[int64]string),
-		sessionStore:   make(map[int64]bool),
-		adminID:        int64(adminID),
-	}, nil
+		dialogs:        newDialogManager(dialogRepo, cfg.DialogTimeout),
+		commands:       newCommandRegistry(),
+		config:         cfg,
+		stop:           make(chan context.Context, 1),
+		done:           make(chan struct{}),
+		adminID:        cfg.AdminID,
+	}
+	s.registerCommands()
+	s.registerTeamCommands()
+	s.registerCollectionCommands()
+	s.registerWishCommands()
+	s.registerWishlistCommands()
+	s.registerAdminCommands()
+	s.registerDialogs()
+	s.registerTeamDialogs()
+	s.registerCollectionDialogs()
+	s.registerWishDialogs()
+	s.registerWishlistDialogs()
+	s.registerAdminDialogs()
+	s.registerCallbacks()
+
+	return s, nil
 }
 
-func (s *BotService) Start() {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+// registerCommands описывает команды бота. Порядок регистрации определяет порядок в /help.
+func (s *BotService) registerCommands() {
+	loginUsage, registerUsage := "<username> <password>", "<username> <password> [YYYY-MM-DD]"
+	if s.authService.Passwordless() {
+		loginUsage, registerUsage = "[PIN]", "<username> [YYYY-MM-DD]"
+	}
 
-	updates := s.bot.GetUpdatesChan(u)
+	s.commands.add(&command{name: "/start", description: "Начало работы с ботом.", public: true,
+		handler: s.handleStartCommand})
+	s.commands.add(&command{name: "/help", usage: "[команда]", description: "Список команд или формат указанной команды.", public: true,
+		handler: s.handleHelpCommand})
+	s.commands.add(&command{name: "/login", usage: loginUsage, description: "Вход в аккаунт.", public: true,
+		handler: s.handleLoginCommand})
+	s.commands.add(&command{name: "/register", usage: registerUsage, description: "Регистрация нового аккаунта.", public: true,
+		handler: s.handleRegisterCommand})
+	if !s.authService.Passwordless() {
+		s.commands.add(&command{name: "/recover", usage: "[<код> <новый пароль>]", description: "Восстановление забытого пароля по коду.", public: true,
+			handler: s.handleRecoverCommand})
+	}
+	s.commands.add(&command{name: "/cancel", description: "Отмена текущей многошаговой команды.", public: true,
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleCancelCommand(m) }})
+	s.commands.add(&command{name: "/logout", description: "Выйти из аккаунта.",
+		handler: func(m *tgbotapi.Message, _ []string) { s.handeLogoutCommand(m) }})
+	s.commands.add(&command{name: "/sessions", usage: "[revoke <chat_id>|all]", description: "Список активных сессий и их завершение.",
+		handler: s.handleSessionsCommand})
+	s.commands.add(&command{name: "/setbirthday", usage: "<YYYY-MM-DD>", description: "Установка даты рождения.",
+		handler: s.handleSetBirthdayCommand})
+	s.commands.add(&command{name: "/userslist", description: "Список всех пользователей с кнопками подписки.",
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleUsersListCommand(m) }})
+	s.commands.add(&command{name: "/subscribe", usage: "<username>", description: "Подписка на уведомления о днях рождения пользователя.",
+		handler: s.handleSubscribeCommand})
+	s.commands.add(&command{name: "/unsubscribe", usage: "<username>", description: "Отписка от уведомлений о днях рождения пользователя.",
+		handler: s.handleUnsubscribeCommand})
+	s.commands.add(&command{name: "/getallsubscriptions", description: "Список пользователей, на которых вы подписаны.",
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleGetAllUserSubscriptions(m) }})
+	s.commands.add(&command{name: "/upcoming", description: "Ближайшие дни рождения из ваших подписок.",
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleUpcomingCommand(m) }})
+	s.commands.add(&command{name: "/reminders", usage: "<дни...>", description: "За сколько дней до дня рождения присылать напоминания.",
+		handler: s.handleRemindersCommand})
+	s.commands.add(&command{name: "/settimezone", usage: "<Area/City>", description: "Установка часового пояса.",
+		handler: s.handleSetTimezoneCommand})
+	s.commands.add(&command{name: "/setnotifytime", usage: "<HH:MM>", description: "Время, в которое приходят напоминания.",
+		handler: s.handleSetNotifyTimeCommand})
+	if s.authService.Passwordless() {
+		s.commands.add(&command{name: "/setpin", usage: "<PIN|->", description: "PIN-код для входа, - чтобы удалить его.",
+			handler: s.handleSetPINCommand})
+	} else {
+		s.commands.add(&command{name: "/changepassword", usage: "<старый пароль> <новый пароль>", description: "Смена пароля.",
+			handler: s.handleChangePasswordCommand})
+	}
+	s.commands.add(&command{name: "/exportmydata", description: "Выгрузить все ваши данные в JSON-файл.",
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleExportMyDataCommand(m) }})
+	s.commands.add(&command{name: "/deleteaccount", description: "Удалить аккаунт и все ваши данные.",
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleDeleteAccountCommand(m) }})
+}
 
-	for update := range updates {
-		if update.Message != nil {
-			s.handleMessage(update.Message)
-		}
+// registerDialogs описывает многошаговые команды и значения, которые они собирают.
+func (s *BotService) registerDialogs() {
+	if s.authService.Passwordless() {
+		s.registerPasswordlessDialogs()
+	} else {
+		s.dialogs.register("/login", dialogFlow{
+			fields: []dialogField{
+				{key: "username", prompt: "Введите username.", validate: validateUsername},
+				{key: "password", prompt: "Введите password.", validate: validateNotEmpty, secret: true},
+			},
+			finish: func(message *tgbotapi.Message, values map[string]string) {
+				s.handleLoginCommandArgs(message, values["username"], values["password"])
+			},
+		})
+		s.dialogs.register("/register", dialogFlow{
+			fields: []dialogField{
+				{key: "username", prompt: "Придумайте username.", validate: validateUsername},
+				{key: "password", prompt: "Придумайте password.", validate: validateNotEmpty, secret: true},
+				{key: "birthday", prompt: "Введите дату рождения в формате YYYY-MM-DD или - чтобы указать её позже.", validate: validateOptionalDate},
+			},
+			finish: func(message *tgbotapi.Message, values map[string]string) {
+				s.handleRegisterCommandArgs(message, values["username"], values["password"], values["birthday"])
+			},
+		})
+		s.registerPasswordDialogs()
 	}
+	s.dialogs.register("/setbirthday", dialogFlow{
+		fields: []dialogField{
+			{key: "birthday", prompt: "Введите дату рождения в формате YYYY-MM-DD.", validate: validateDate},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleSetBirthdayCommandArgs(message, values["birthday"])
+		},
+	})
+	s.dialogs.register("/subscribe", dialogFlow{
+		fields: []dialogField{
+			{key: "username", prompt: "Введите имя пользователя на которого хотите подписаться.", validate: validateUsername},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleSubscribeCommandArgs(message, values["username"])
+		},
+	})
+	s.dialogs.register("/unsubscribe", dialogFlow{
+		fields: []dialogField{
+			{key: "username", prompt: "Введите имя пользователя от которого хотите отписаться.", validate: validateUsername},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleUnsubscribeCommandArgs(message, values["username"])
+		},
+	})
+	s.dialogs.register("/reminders", dialogFlow{
+		fields: []dialogField{
+			{key: "days", prompt: "Введите за сколько дней до дня рождения напоминать, через пробел. Например: 7 3 0", validate: validateOffsets, variadic: true},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleRemindersCommandArgs(message, strings.Fields(values["days"]))
+		},
+	})
+	s.dialogs.register("/settimezone", dialogFlow{
+		fields: []dialogField{
+			{key: "timezone", prompt: "Введите часовой пояс, например Europe/Moscow или Asia/Yekaterinburg.", validate: validateSingleWord},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleSetTimezoneCommandArgs(message, values["timezone"])
+		},
+	})
+	s.dialogs.register("/setnotifytime", dialogFlow{
+		fields: []dialogField{
+			{key: "time", prompt: "Введите время в формате HH:MM.", validate: validateClock},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleSetNotifyTimeCommandArgs(message, values["time"])
+		},
+	})
 }
 
-func (s *BotService) handleMessage(message *tgbotapi.Message) {
-	if cmd, exists := s.pendingCmd[message.Chat.ID]; exists {
-		s.handleCommandResponse(message, cmd)
-		return
-	}
-
-	args := strings.Split(message.Text, " ")
-	switch args[0] {
-	case "/start":
-		s.handleStartCommand(message)
-	case "/login":
-		s.handleLoginCommand(message)
-	case "/register":
-		s.handleRegisterCommand(message)
-	default:
-		if !s.isLoggedIn(message.Chat.ID) {
-			msg := tgbotapi.NewMessage(message.Chat.ID, "Вы должны сначала ввойти в аккаунт.\nИспользуйте команду /login username password.")
-			s.bot.Send(msg)
-			return
-		}
-		switch args[0] {
-		case "/setbirthday":
-			s.handleSetBirthdayCommand(message)
-		case "/userslist":
-			s.handleUsersListCommand(message)
-		case "/subscribe":
-			s.handleSubscribeCommand(message)
-		case "/unsubscribe":
-			s.handleUnsubscribeCommand(message)
-		case "/getallsubscriptions":
-			s.handleGetAllUserSubscriptions(message)
-		case "/logout":
-			s.handeLogoutCommand(message)
-		default:
-			msg := tgbotapi.NewMessage(message.Chat.ID, "Неизвестная команда.")
-			s.bot.Send(msg)
-		}
+// RunDialogCleanup периодически удаляет брошенные многошаговые команды, пока не будет отменён ctx.
+func (s *BotService) RunDialogCleanup(ctx context.Context, interval time.Duration) {
+	s.dialogs.runCleanup(ctx, interval)
+}
+
+// Start получает обновления выбранным способом и распределяет их по
+// обработчикам. Возвращается после Stop, когда все принятые обновления
+// обработаны, или с ошибкой, если получать обновления не удалось.
+func (s *BotService) Start() error {
+	defer close(s.done)
+
+	pool := newDispatcher(s.config.Workers, s.config.QueueSize, s.handleUpdate)
+	defer pool.stop()
+
+	if s.config.Mode == config.ModeWebhook {
+		return s.serveWebhook(pool)
 	}
+	return s.poll(pool)
 }
 
-func (s *BotService) isLoggedIn(chatID int64) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.sessionStore[chatID]
+// Stop прекращает получение обновлений и ждёт, пока обработаются уже принятые,
+// но не дольше, чем позволяет ctx.
+func (s *BotService) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		s.bot.StopReceivingUpdates()
+		s.stop <- ctx
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func (s *BotService) setLoggedIn(chatID int64, status bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.sessionStore[chatID] = status
+func (s *BotService) handleUpdate(update tgbotapi.Update) {
+	if update.Message != nil {
+		s.handleMessage(update.Message)
+	}
+	if update.CallbackQuery != nil {
+		s.handleCallbackQuery(update.CallbackQuery)
+	}
 }
 
-func (s *BotService) handleCommandResponse(message *tgbotapi.Message, cmd string) {
-	args := strings.Split(message.Text, " ")
+func (s *BotService) handleMessage(message *tgbotapi.Message) {
+	text := strings.TrimSpace(message.Text)
+	isCommand := strings.HasPrefix(text, "/")
 
-	switch cmd {
-	case "/login":
-		if len(args) != 2 {
-			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Неверный формат входа. Используйте username password"))
-			s.pendingCmd[message.Chat.ID] = "/login"
-			return
-		}
-		s.handleLoginCommandArgs(message, args)
-	case "/register":
-		if len(args) != 2 {
-			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Неверный формат регистрации. Используйте username password"))
-			return
-		}
-		s.handleRegisterCommandArgs(message, args)
-	case "/setbirthday":
-		if len(args) != 1 {
-			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Неверный формат даты. Используйте YYYY-MM-DD"))
-			s.pendingCmd[message.Chat.ID] = "/setbirthday"
-			return
-		}
-		s.handleSetBirthdayCommandArgs(message, args[0])
-	case "/subscribe":
-		if len(args) != 1 {
-			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Неверный формат. Используйте username"))
-			s.pendingCmd[message.Chat.ID] = "/subscribe"
-			return
-		}
-		s.handleSubscribeCommandArgs(message, args[0])
-	case "/unsubscribe":
-		if len(args) != 1 {
-			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Неверный формат. Используйте username"))
-			s.pendingCmd[message.Chat.ID] = "/unsubscribe"
-			return
-		}
-		s.handleUnsubscribeCommandArgs(message, args[0])
+	state, expired := s.dialogs.current(message.Chat.ID)
+	switch {
+	case expired && !isCommand:
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Время ожидания ответа для команды "+state.Command+" истекло. Повторите команду.")
+		s.bot.Send(msg)
+		return
+	case state != nil && !expired && !isCommand:
+		s.handleDialogInput(message, state)
+		return
+	}
+
+	name, args, err := parseCommand(text)
+	if err != nil {
+		s.sendUsage(message, name, err)
+		return
+	}
+
+	if state != nil && !expired && name != "/cancel" {
+		// Новая команда прерывает незавершённую.
+		s.dialogs.end(message.Chat.ID)
+	}
+
+	cmd, ok := s.commands.get(name)
+	if !ok {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Неизвестная команда. Список команд: /help")
+		s.bot.Send(msg)
+		return
+	}
+
+	if !s.checkAccess(message, cmd) {
+		return
 	}
-	delete(s.pendingCmd, message.Chat.ID)
+
+	cmd.handler(message, args)
+}
+
+// checkAccess проверяет, что команду cmd можно выполнить: пользователь вошёл
+// в аккаунт, если команда не публичная, и у него достаточно прав. Иначе
+// сообщает об этом и возвращает false.
+func (s *BotService) checkAccess(message *tgbotapi.Message, cmd *command) bool {
+	if !cmd.public && !s.isLoggedIn(message.Chat.ID) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Вы должны сначала ввойти в аккаунт.\n"+s.loginHint())
+		s.bot.Send(msg)
+		return false
+	}
+
+	if cmd.role != "" && !s.currentRole(message.From.ID).AtLeast(cmd.role) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Недостаточно прав для этой команды.")
+		s.bot.Send(msg)
+		return false
+	}
+	return true
+}
+
+func (s *BotService) isLoggedIn(chatID int64) bool {
+	return s.sessions.IsLoggedIn(chatID)
 }
 
-func (s *BotService) handleStartCommand(message *tgbotapi.Message) {
+// handleStartCommand приветствует пользователя. Параметр wishlist_<username>
+// из ссылки на список желаний сразу открывает этот список.
+func (s *BotService) handleStartCommand(message *tgbotapi.Message, args []string) {
+	var wishlistOwner string
+	if len(args) == 1 && strings.HasPrefix(args[0], wishlistStartPrefix) {
+		wishlistOwner = strings.TrimPrefix(args[0], wishlistStartPrefix)
+	}
+
 	if s.isLoggedIn(message.Chat.ID) {
+		if wishlistOwner != "" {
+			s.sendWishlist(message, wishlistOwner)
+			return
+		}
 		msg := tgbotapi.NewMessage(message.Chat.ID, "Вы можете теперь подписываться и отписываться на день рождения других пользователей, получать список своих подписок и других пользователей.")
 		s.bot.Send(msg)
 		return
 	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Добро пожаловать в бота BirthdayGreetings. Вы можете зарегистрироваться либо войти в свой аккаунт. Для этого введите /login или /register username и password.")
+	text := "Добро пожаловать в бота BirthdayGreetings. Вы можете зарегистрироваться либо войти в свой аккаунт. Для этого введите /login или /register username и password."
+	if s.authService.Passwordless() {
+		text = "Добро пожаловать в бота BirthdayGreetings. Вы можете зарегистрироваться командой /register username либо войти в свой аккаунт командой /login. Пароль не нужен: вход выполняется по вашему Telegram аккаунту."
+	}
+	if wishlistOwner != "" {
+		text += "\nПосле входа список желаний можно открыть командой /wishlist @" + wishlistOwner + "."
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	s.bot.Send(msg)
 }
 
-func (s *BotService) handleLoginCommand(message *tgbotapi.Message) {
+func (s *BotService) handleLoginCommand(message *tgbotapi.Message, args []string) {
 	if s.isLoggedIn(message.Chat.ID) {
 		msg := tgbotapi.NewMessage(message.Chat.ID, "Вы уже в аккаунте.")
 		s.bot.Send(msg)
 		return
 	}
 
-	s.pendingCmd[message.Chat.ID] = "/login"
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйства введите username и password.")
-	s.bot.Send(msg)
+	if s.authService.Passwordless() {
+		s.handleTelegramLoginCommand(message, args)
+		return
+	}
+	s.startDialog(message, "/login", args, "")
 }
 
-func (s *BotService) handleLoginCommandArgs(message *tgbotapi.Message, args []string) {
-	username := args[0]
-	password := args[1]
+func (s *BotService) handleLoginCommandArgs(message *tgbotapi.Message, username, password string) {
 	telegramID := message.From.ID
 
 	name, err := s.authService.AuthenticateUser(username, password, telegramID)
@@ -194,12 +408,23 @@ func (s *BotService) handleLoginCommandArgs(message *tgbotapi.Message, args []st
 		s.bot.Send(msg)
 		return
 	}
-	s.setLoggedIn(message.Chat.ID, true)
+
+	user, err := s.userService.GetUserByTgID(telegramID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Ошибка входа: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+	if err := s.sessions.Login(message.Chat.ID, user.ID); err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Ошибка входа: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
 	msg := tgbotapi.NewMessage(message.Chat.ID, "Вход успешный. Добро пожаловать, "+name)
 	s.bot.Send(msg)
 }
 
-func (s *BotService) handleRegisterCommand(message *tgbotapi.Message) {
+func (s *BotService) handleRegisterCommand(message *tgbotapi.Message, args []string) {
 	if s.isLoggedIn(message.Chat.ID) {
 		msg := tgbotapi.NewMessage(message.Chat.ID, "Вы уже вошли в аккаунт.")
 		s.bot.Send(msg)
@@ -213,14 +438,10 @@ func (s *BotService) handleRegisterCommand(message *tgbotapi.Message) {
 		return
 	}
 
-	s.pendingCmd[message.Chat.ID] = "/register"
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Пожалуйства введите username и password.")
-	s.bot.Send(msg)
+	s.startDialog(message, "/register", args, "")
 }
 
-func (s *BotService) handleRegisterCommandArgs(message *tgbotapi.Message, args []string) {
-	username := args[0]
-	password := args[1]
+func (s *BotService) handleRegisterCommandArgs(message *tgbotapi.Message, username, password, birthday string) {
 	telegramID := message.From.ID
 
 	err := s.authService.RegisterUser(username, password, telegramID)
@@ -230,14 +451,24 @@ func (s *BotService) handleRegisterCommandArgs(message *tgbotapi.Message, args [
 		return
 	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Регистрация успешна! Теперь вы можете войти, используя команду /login.\nСразу же после этого введите вашу дату рождения командой /setbirthday")
+	if birthday == "" || birthday == "-" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Регистрация успешна! Теперь вы можете войти, используя команду /login.\nСразу же после этого введите вашу дату рождения командой /setbirthday")
+		s.bot.Send(msg)
+		return
+	}
+
+	if err := s.userService.SetUserBirthday(telegramID, birthday); err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Регистрация успешна, но дату рождения сохранить не удалось: "+err.Error()+"\nВойдите командой /login и укажите её командой /setbirthday")
+		s.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Регистрация успешна! Теперь вы можете войти, используя команду /login.")
 	s.bot.Send(msg)
 }
 
-func (s *BotService) handleSetBirthdayCommand(message *tgbotapi.Message) {
-	s.pendingCmd[message.Chat.ID] = "/setbirthday"
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Введите дату рождения в формате YYYY-MM-DD.")
-	s.bot.Send(msg)
+func (s *BotService) handleSetBirthdayCommand(message *tgbotapi.Message, args []string) {
+	s.startDialog(message, "/setbirthday", args, "")
 }
 
 func (s *BotService) handleSetBirthdayCommandArgs(message *tgbotapi.Message, birthday string) {
@@ -255,37 +486,88 @@ func (s *BotService) handleSetBirthdayCommandArgs(message *tgbotapi.Message, bir
 }
 
 func (s *BotService) handeLogoutCommand(message *tgbotapi.Message) {
-	s.setLoggedIn(message.Chat.ID, false)
+	if err := s.sessions.Logout(message.Chat.ID); err != nil {
+		logging.Logger.Printf("Ошибка в завершении сессии %d: %v", message.Chat.ID, err)
+	}
 	msg := tgbotapi.NewMessage(message.Chat.ID, "Logout")
 	s.bot.Send(msg)
 }
 
-func (s *BotService) handleUsersListCommand(message *tgbotapi.Message) {
-	users, err := s.userService.GetAllUsers()
+// handleSessionsCommand показывает активные сессии пользователя.
+// "/sessions revoke <chat_id>" завершает одну сессию, "/sessions revoke all" - все, кроме текущей.
+func (s *BotService) handleSessionsCommand(message *tgbotapi.Message, args []string) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Ошибка в получении пользователей: "+err.Error())
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error())
 		s.bot.Send(msg)
 		return
 	}
 
-	var userList string
-	for _, user := range users {
-		if user.TelegramID == message.From.ID {
-			continue
+	if len(args) > 0 {
+		if args[0] != "revoke" || len(args) != 2 {
+			msg := tgbotapi.NewMessage(message.Chat.ID, "Неверный формат. Используйте /sessions, /sessions revoke <chat_id> или /sessions revoke all")
+			s.bot.Send(msg)
+			return
 		}
-		userList += fmt.Sprintf("Пользователь: %s, Дата рождения: %s\n", user.Username, fmt.Sprint(user.Birthday.Day())+"."+user.Birthday.Month().String()+"."+fmt.Sprint(user.Birthday.Year()))
+		s.handleRevokeSession(message, user.ID, args[1])
+		return
 	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, userList)
+	sessions, err := s.sessions.GetUserSessions(user.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить сессии: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	returnMessage := "Активные сессии:\n"
+	for _, session := range sessions {
+		returnMessage += fmt.Sprintf("Чат %d: вход %s, активность %s", session.ChatID,
+			session.CreatedAt.Format("02.01.2006 15:04"), session.LastSeenAt.Format("02.01.2006 15:04"))
+		if session.ChatID == message.Chat.ID {
+			returnMessage += " (текущая)"
+		}
+		returnMessage += "\n"
+	}
+	returnMessage += "\nЗавершить сессию: /sessions revoke <chat_id>, все остальные: /sessions revoke all"
+	msg := tgbotapi.NewMessage(message.Chat.ID, returnMessage)
 	s.bot.Send(msg)
 }
 
-func (s *BotService) handleSubscribeCommand(message *tgbotapi.Message) {
-	s.pendingCmd[message.Chat.ID] = "/subscribe"
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Введите имя пользователя на которого хотите подписаться.")
+func (s *BotService) handleRevokeSession(message *tgbotapi.Message, userID int64, target string) {
+	if target == "all" {
+		revoked, err := s.sessions.RevokeOthers(userID, message.Chat.ID)
+		if err != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось завершить сессии: "+err.Error())
+			s.bot.Send(msg)
+			return
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Завершено сессий: %d", revoked))
+		s.bot.Send(msg)
+		return
+	}
+
+	chatID, err := strconv.ParseInt(target, 10, 64)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Неверный идентификатор чата: "+target)
+		s.bot.Send(msg)
+		return
+	}
+
+	if err := s.sessions.Revoke(userID, chatID); err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось завершить сессию: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Сессия завершена.")
 	s.bot.Send(msg)
 }
 
+func (s *BotService) handleSubscribeCommand(message *tgbotapi.Message, args []string) {
+	s.startDialog(message, "/subscribe", args, "")
+}
+
 func (s *BotService) handleSubscribeCommandArgs(message *tgbotapi.Message, username string) {
 	currentUser, err := s.userService.GetUserByTgID(message.From.ID)
 	if err != nil {
@@ -318,10 +600,8 @@ func (s *BotService) handleSubscribeCommandArgs(message *tgbotapi.Message, usern
 	s.bot.Send(msg)
 }
 
-func (s *BotService) handleUnsubscribeCommand(message *tgbotapi.Message) {
-	s.pendingCmd[message.Chat.ID] = "/unsubscribe"
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Введите имя пользователя от которого хотите отписаться.")
-	s.bot.Send(msg)
+func (s *BotService) handleUnsubscribeCommand(message *tgbotapi.Message, args []string) {
+	s.startDialog(message, "/unsubscribe", args, "")
 }
 
 func (s *BotService) handleUnsubscribeCommandArgs(message *tgbotapi.Message, username string) {
@@ -375,6 +655,208 @@ func (s *BotService) handleGetAllUserSubscriptions(message *tgbotapi.Message) {
 
 }
 
+// upcomingDays - на сколько дней вперёд /upcoming показывает дни рождения.
+const upcomingDays = 30
+
+func (s *BotService) handleUpcomingCommand(message *tgbotapi.Message) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+	subscriptions, err := s.subService.GetSubscriptions(user.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить подписки пользователя: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	today := time.Now().In(service.Location(user.Timezone))
+	type upcoming struct {
+		username string
+		date     time.Time
+		days     int
+	}
+	var list []upcoming
+	for _, sub := range subscriptions {
+		if !birthday.IsSet(sub.Birthday) {
+			continue
+		}
+		days := s.matcher.DaysUntil(sub.Birthday, today)
+		if days <= upcomingDays {
+			list = append(list, upcoming{username: sub.Username, date: s.matcher.Next(sub.Birthday, today), days: days})
+		}
+	}
+
+	if len(list) == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("В ближайшие %d дней дней рождения нет.", upcomingDays))
+		s.bot.Send(msg)
+		return
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].days < list[j].days
+	})
+
+	returnMessage := "Ближайшие дни рождения:\n"
+	for _, u := range list {
+		when := "сегодня"
+		if u.days > 0 {
+			when = fmt.Sprintf("через %d дн.", u.days)
+		}
+		returnMessage += fmt.Sprintf("%s - %s (%s)\n", u.date.Format("02.01"), u.username, when)
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, returnMessage)
+	s.bot.Send(msg)
+}
+
+func (s *BotService) handleRemindersCommand(message *tgbotapi.Message, args []string) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	offsets, err := s.reminders.GetOffsets(user.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить напоминания: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	current := make([]string, 0, len(offsets))
+	for _, days := range offsets {
+		current = append(current, reminder.FormatOffset(days))
+	}
+
+	s.startDialog(message, "/reminders", args, "Сейчас напоминания приходят: "+strings.Join(current, ", ")+".")
+}
+
+func (s *BotService) handleRemindersCommandArgs(message *tgbotapi.Message, args []string) {
+	offsets, err := reminder.ParseOffsets(args)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	if err := s.reminders.SetOffsets(user.ID, offsets); err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось сохранить напоминания: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Напоминания успешно сохранены.")
+	s.bot.Send(msg)
+}
+
+func (s *BotService) handleSetTimezoneCommand(message *tgbotapi.Message, args []string) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	current := user.Timezone
+	if current == "" {
+		current = "часовой пояс сервера"
+	}
+
+	s.startDialog(message, "/settimezone", args, "Сейчас используется "+current+".")
+}
+
+func (s *BotService) handleSetTimezoneCommandArgs(message *tgbotapi.Message, timezone string) {
+	err := s.userService.SetUserTimezone(message.From.ID, timezone)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Ошибка обновления часового пояса: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Часовой пояс успешно изменён.")
+	s.bot.Send(msg)
+}
+
+func (s *BotService) handleSetNotifyTimeCommand(message *tgbotapi.Message, args []string) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	s.startDialog(message, "/setnotifytime", args, "Сейчас уведомления приходят в "+user.NotifyTime+".")
+}
+
+func (s *BotService) handleSetNotifyTimeCommandArgs(message *tgbotapi.Message, notifyTime string) {
+	err := s.userService.SetUserNotifyTime(message.From.ID, notifyTime)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Ошибка обновления времени уведомлений: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "Время уведомлений успешно изменено.")
+	s.bot.Send(msg)
+}
+
+// SendMessage отправляет личное сообщение пользователю. Для личного чата с ботом
+// идентификатор чата совпадает с telegram ID пользователя.
+func (s *BotService) SendMessage(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := s.bot.Send(msg)
+	return err
+}
+
+// sendLines отправляет в чат chatID список lines под заголовком header.
+// Если список не помещается в одно сообщение Telegram, он разбивается на
+// несколько сообщений по границам строк.
+func (s *BotService) sendLines(chatID int64, header string, lines []string) error {
+	for _, text := range splitLines(header, lines, maxMessageLength) {
+		if err := s.SendMessage(chatID, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLines собирает header и lines в сообщения не длиннее limit символов.
+// Строка, которая не помещается в сообщение целиком, обрезается.
+func splitLines(header string, lines []string, limit int) []string {
+	var messages []string
+	current, length := header, utf8.RuneCountInString(header)
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) > limit {
+			line = string([]rune(line)[:limit-1]) + "…"
+		}
+		n := utf8.RuneCountInString(line)
+		if length > 0 && length+1+n > limit {
+			messages = append(messages, current)
+			current, length = "", 0
+		}
+		if length > 0 {
+			current += "\n"
+			length++
+		}
+		current += line
+		length += n
+	}
+	if length > 0 {
+		messages = append(messages, current)
+	}
+	return messages
+}
+
 func (s *BotService) SendMessageToChannel(ctx context.Context, channelID int64, message string) error {
 	msg := tgbotapi.NewMessage(channelID, message)
 	_, err := s.bot.Send(msg)
@@ -382,7 +864,7 @@ func (s *BotService) SendMessageToChannel(ctx context.Context, channelID int64,
 }
 
 func (s *BotService) GetBotID() int64 {
-	return s.bot.Self.ID
+	return s.self.ID
 }
 
 func (s *BotService) GetAdminID() int64 {
@@ -0,0 +1,281 @@
+package bot
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"BirthdayGreetings/internal/account"
+	"BirthdayGreetings/internal/admin"
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/auth"
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/collection"
+	"BirthdayGreetings/internal/config"
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/reminder"
+	"BirthdayGreetings/internal/service"
+	"BirthdayGreetings/internal/session"
+	"BirthdayGreetings/internal/subscription"
+	"BirthdayGreetings/internal/team"
+	"BirthdayGreetings/internal/wish"
+	"BirthdayGreetings/internal/wishlist"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestMain(m *testing.M) {
+	logging.Logger = log.New(io.Discard, "", 0)
+	os.Exit(m.Run())
+}
+
+// fakeAPI запоминает отправленные ботом сообщения вместо обращения к Telegram.
+type fakeAPI struct {
+	mu   sync.Mutex
+	sent []tgbotapi.Chattable
+}
+
+func (f *fakeAPI) GetMe() (tgbotapi.User, error) {
+	return tgbotapi.User{ID: 1, IsBot: true, UserName: "test_bot"}, nil
+}
+
+func (f *fakeAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, c)
+	return tgbotapi.Message{}, nil
+}
+
+func (f *fakeAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeAPI) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return make(chan tgbotapi.Update)
+}
+
+func (f *fakeAPI) StopReceivingUpdates() {}
+
+func (f *fakeAPI) HandleUpdate(*http.Request) (*tgbotapi.Update, error) {
+	return nil, errors.New(400, "webhook не поддерживается")
+}
+
+// texts возвращает тексты сообщений, отправленных в chatID, и очищает список.
+func (f *fakeAPI) texts(chatID int64) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var texts []string
+	for _, c := range f.sent {
+		if msg, ok := c.(tgbotapi.MessageConfig); ok && msg.ChatID == chatID {
+			texts = append(texts, msg.Text)
+		}
+	}
+	f.sent = nil
+	return texts
+}
+
+// newTestBot создаёт бота на репозиториях в памяти со входом без пароля и
+// пользователем bob (Telegram ID 200).
+func newTestBot(t *testing.T) (*BotService, *fakeAPI) {
+	t.Helper()
+	users := db.NewMemoryUserRepository()
+	auditService := audit.NewAuditService(db.NewMemoryAuditRepository(users))
+	userService := service.NewUserService(users, auditService)
+	subService := subscription.NewSubscriptionService(db.NewMemorySubscriptionRepository(users), auditService)
+	reminderService := reminder.NewReminderService(db.NewMemoryReminderRepository(users))
+	matcher := birthday.NewMatcher(birthday.LeapFeb28)
+	sessions := session.NewSessionService(db.NewMemorySessionRepository(users), time.Hour, 24*time.Hour)
+	limiter := auth.NewLoginLimiter(db.NewMemoryLoginAttemptRepository(), 5, time.Minute, time.Hour)
+	authService := auth.NewAuthService(userService, limiter, db.NewMemoryRecoveryCodeRepository(users), auditService, config.LoginTelegram)
+	adminService := admin.NewAdminService(userService, sessions, limiter, auditService, 0)
+	teamService := team.NewTeamService(db.NewMemoryTeamRepository(users), userService, auditService)
+	accountService := account.NewAccountService(userService, subService, reminderService, teamService, db.NewMemoryNotificationRepository(users), limiter, auditService)
+	collectionService := collection.NewCollectionService(db.NewMemoryCollectionRepository(users), subService, 7)
+	wishService := wish.NewWishService(db.NewMemoryWishRepository(users), userService, matcher)
+	wishlistService := wishlist.NewWishlistService(db.NewMemoryWishlistRepository(users), userService, subService)
+
+	api := &fakeAPI{}
+	cfg := config.Bot{DialogTimeout: time.Minute, Workers: 1, QueueSize: 1}
+	s, err := NewBotService(api, authService, userService, subService, reminderService, matcher, sessions, adminService, accountService,
+		teamService, collectionService, wishService, wishlistService, db.NewMemoryDialogRepository(), cfg, nil)
+	if err != nil {
+		t.Fatalf("NewBotService() error = %v", err)
+	}
+	if err := authService.RegisterUser("bob", "", 200); err != nil {
+		t.Fatalf("RegisterUser(bob) error = %v", err)
+	}
+	return s, api
+}
+
+// send передаёт боту сообщение text от пользователя с Telegram ID id в его личный чат.
+func send(s *BotService, id int64, text string) {
+	sendTo(s, &tgbotapi.Chat{ID: id, Type: "private"}, id, text)
+}
+
+// sendTo передаёт боту сообщение text от пользователя с Telegram ID id в чат chat.
+func sendTo(s *BotService, chat *tgbotapi.Chat, id int64, text string) {
+	s.handleUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 1,
+		From:      &tgbotapi.User{ID: id},
+		Chat:      chat,
+		Text:      text,
+	}})
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     string
+	}{
+		{"неизвестная команда", []string{"/nosuchcommand"}, "Неизвестная команда"},
+		{"команда без входа", []string{"/getallsubscriptions"}, "Вы должны сначала ввойти в аккаунт"},
+		{"вход без регистрации", []string{"/login"}, "не привязан пользователь"},
+		{"регистрация и вход", []string{"/register alice -", "/login"}, "Вход успешный"},
+		{"подписка после входа", []string{"/register alice -", "/login", "/subscribe bob"}, "Успешная подписка на пользователя bob"},
+		{"подписка на себя", []string{"/register alice -", "/login", "/subscribe alice"}, "сами на себя"},
+		{"повторная подписка", []string{"/register alice -", "/login", "/subscribe bob", "/subscribe bob"}, "Ошибка при подписке"},
+		{"команда администратора", []string{"/register alice -", "/login", "/users"}, "Недостаточно прав"},
+		{"многошаговая команда", []string{"/register alice -", "/login", "/subscribe", "bob"}, "Успешная подписка на пользователя bob"},
+		{"отмена многошаговой команды", []string{"/register alice -", "/login", "/subscribe", "/cancel"}, "отменена"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, api := newTestBot(t)
+			for _, text := range tt.messages {
+				send(s, 100, text)
+			}
+
+			replies := api.texts(100)
+			if len(replies) == 0 {
+				t.Fatal("бот не ответил")
+			}
+			if last := replies[len(replies)-1]; !strings.Contains(last, tt.want) {
+				t.Errorf("ответ на %q = %q, want содержит %q", tt.messages[len(tt.messages)-1], last, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribeCommand(t *testing.T) {
+	s, api := newTestBot(t)
+	send(s, 100, "/register alice -")
+	send(s, 100, "/login")
+	send(s, 100, "/subscribe bob")
+	api.texts(100)
+
+	alice, err := s.userService.GetUserByTgID(100)
+	if err != nil {
+		t.Fatalf("GetUserByTgID() error = %v", err)
+	}
+	bob, _ := s.userService.GetUserByName("bob")
+	subscribed, err := s.subService.IsSubscribed(alice.ID, bob.ID)
+	if err != nil || !subscribed {
+		t.Errorf("IsSubscribed() = %v, %v, want true", subscribed, err)
+	}
+}
+
+// deliveredWishes запоминает пожелания, переданные на доставку.
+type deliveredWishes []models.Wish
+
+func (d *deliveredWishes) DeliverWish(w models.Wish) error {
+	*d = append(*d, w)
+	return nil
+}
+
+func TestWishOnBirthday(t *testing.T) {
+	s, api := newTestBot(t)
+	var delivered deliveredWishes
+	s.SetWishDeliverer(&delivered)
+	if err := s.userService.SetUserBirthday(200, "2000-"+time.Now().Format("01-02")); err != nil {
+		t.Fatalf("SetUserBirthday() error = %v", err)
+	}
+
+	send(s, 100, "/register alice -")
+	send(s, 100, "/login")
+	send(s, 100, "/wish bob   С днём рождения!  ")
+
+	replies := api.texts(100)
+	if last := replies[len(replies)-1]; !strings.Contains(last, "отправлено сразу") {
+		t.Errorf("ответ на /wish = %q", last)
+	}
+	if len(delivered) != 1 {
+		t.Fatalf("доставлено %d пожеланий, want 1", len(delivered))
+	}
+	if w := delivered[0]; w.Text != "С днём рождения!" || w.AuthorName != "alice" {
+		t.Errorf("доставлено пожелание %q от %q", w.Text, w.AuthorName)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{"помещается целиком", []string{"aaa", "bbb"}, []string{"H:\naaa\nbbb"}},
+		{"перенос по строкам", []string{"aaaa", "bbbb", "cc"}, []string{"H:\naaaa", "bbbb\ncc"}},
+		{"длинная строка", []string{"aaaaaaaaaaaa"}, []string{"H:", "aaaaaaaaa…"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitLines("H:", tt.lines, 10)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitLines() = %q, want %q", got, tt.want)
+			}
+			for _, m := range got {
+				if n := utf8.RuneCountInString(m); n > 10 {
+					t.Errorf("сообщение %q длиной %d больше предела", m, n)
+				}
+			}
+		})
+	}
+}
+
+func TestWishlistSentPrivately(t *testing.T) {
+	s, api := newTestBot(t)
+	send(s, 100, "/register alice -")
+	send(s, 100, "/login")
+	send(s, 100, "/subscribe bob")
+	bob, _ := s.userService.GetUserByName("bob")
+	if _, err := s.wishlists.Add(bob, "Книга"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	api.texts(100)
+
+	const groupID = -500
+	group := &tgbotapi.Chat{ID: groupID, Type: "group"}
+	sendTo(s, group, 100, "/login")
+	api.texts(groupID)
+	sendTo(s, group, 100, "/wishlist @bob")
+
+	var private, public []string
+	for _, c := range api.sent {
+		if msg, ok := c.(tgbotapi.MessageConfig); ok && msg.ChatID == 100 {
+			private = append(private, msg.Text)
+		} else if ok && msg.ChatID == groupID {
+			public = append(public, msg.Text)
+		}
+	}
+	if len(private) != 1 || !strings.Contains(private[0], "Книга") {
+		t.Errorf("в личные сообщения отправлено %q, want список желаний bob", private)
+	}
+	if len(public) != 1 || strings.Contains(public[0], "Книга") {
+		t.Errorf("в общий чат отправлено %q, want только уведомление", public)
+	}
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"strings"
+
+	"BirthdayGreetings/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackHandler обрабатывает нажатие inline-кнопки. Данные кнопки имеют вид
+// "действие:арг1:арг2", в args передаются аргументы без действия.
+type callbackHandler func(query *tgbotapi.CallbackQuery, args []string)
+
+// callbackData собирает данные inline-кнопки. Telegram ограничивает их 64 байтами.
+func callbackData(action string, args ...string) string {
+	return strings.Join(append([]string{action}, args...), ":")
+}
+
+func (s *BotService) registerCallbacks() {
+	s.callbacks = map[string]callbackHandler{
+		callbackUsersPage:   s.handleUsersPageCallback,
+		callbackSubscribe:   s.handleSubscribeCallback,
+		callbackUnsubscribe: s.handleUnsubscribeCallback,
+
+		callbackDeleteAccount: s.handleDeleteAccountCallback,
+		callbackKeepAccount:   s.handleKeepAccountCallback,
+
+		callbackPledge:   s.handlePledgeCallback,
+		callbackWithdraw: s.handleWithdrawCallback,
+		callbackPaid:     s.handlePaidCallback,
+		callbackOrganize: s.handleOrganizeCallback,
+
+		callbackReserve: s.handleReserveCallback,
+		callbackRelease: s.handleReleaseCallback,
+	}
+}
+
+func (s *BotService) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		s.answerCallback(query, "")
+		return
+	}
+
+	parts := strings.Split(query.Data, ":")
+	handler, ok := s.callbacks[parts[0]]
+	if !ok {
+		s.answerCallback(query, "Неизвестное действие.")
+		return
+	}
+
+	if !s.isLoggedIn(query.Message.Chat.ID) {
+		s.answerCallback(query, "Вы должны сначала ввойти в аккаунт.")
+		return
+	}
+
+	handler(query, parts[1:])
+}
+
+// answerCallback убирает индикатор загрузки с кнопки и, если text не пустой, показывает уведомление.
+func (s *BotService) answerCallback(query *tgbotapi.CallbackQuery, text string) {
+	if _, err := s.bot.Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		logging.Logger.Printf("Ошибка в ответе на нажатие кнопки: %v", err)
+	}
+}
@@ -0,0 +1,366 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"BirthdayGreetings/internal/collection"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackPledge   = "cpledge"
+	callbackWithdraw = "cwithdraw"
+	callbackPaid     = "cpaid"
+	callbackOrganize = "corganize"
+)
+
+// pledgeAmounts - суммы взноса на кнопках. Другую сумму можно указать командой /pledge.
+var pledgeAmounts = []int{300, 500, 1000}
+
+func (s *BotService) registerCollectionCommands() {
+	s.commands.add(&command{name: "/collections", description: "Открытые сборы на подарки, в которых вы участвуете.",
+		handler: func(m *tgbotapi.Message, _ []string) { s.handleCollectionsCommand(m) }})
+	s.commands.add(&command{name: "/collection", usage: "<номер>", description: "Сбор на подарок с кнопками взноса, для организатора - сводка.",
+		handler: s.dialogCommand("/collection")})
+	s.commands.add(&command{name: "/pledge", usage: "<номер> <сумма>", description: "Указать свою сумму взноса в сбор.",
+		handler: s.dialogCommand("/pledge")})
+}
+
+func (s *BotService) registerCollectionDialogs() {
+	id := dialogField{key: "id", prompt: "Введите номер сбора из /collections.", validate: validateCollectionID}
+
+	s.dialogs.register("/collection", dialogFlow{
+		fields: []dialogField{id},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleCollectionCommandArgs(message, values["id"])
+		},
+	})
+	s.dialogs.register("/pledge", dialogFlow{
+		fields: []dialogField{
+			id,
+			{key: "amount", prompt: "Введите сумму взноса.", validate: validateAmount},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handlePledgeCommandArgs(message, values["id"], values["amount"])
+		},
+	})
+}
+
+// SendCollectionInvite приглашает участника в сбор на подарок: отправляет
+// карточку сбора с кнопками взноса.
+func (s *BotService) SendCollectionInvite(telegramID int64, c *models.Collection) error {
+	msg := tgbotapi.NewMessage(telegramID, "Открыт сбор на подарок!\n"+collectionCard(c, nil, 0))
+	msg.ReplyMarkup = collectionKeyboard(c, nil)
+	_, err := s.bot.Send(msg)
+	return err
+}
+
+// SendCollectionSummary отправляет организатору сводку сбора: кто сколько
+// обещал и оплатил и кто ещё не ответил.
+func (s *BotService) SendCollectionSummary(telegramID int64, id int64) error {
+	text, err := s.collectionSummary(id)
+	if err != nil {
+		return err
+	}
+	return s.SendMessage(telegramID, text)
+}
+
+func (s *BotService) collectionSummary(id int64) (string, error) {
+	c, pledges, err := s.collections.Get(id)
+	if err != nil {
+		return "", err
+	}
+	participants, err := s.collections.Participants(c)
+	if err != nil {
+		return "", err
+	}
+
+	pledged, paid := collection.Totals(pledges)
+	lines := []string{
+		fmt.Sprintf("Сводка сбора №%d на подарок для %s (%s)", c.ID, c.Username, c.Birthday.Format("02.01")),
+		fmt.Sprintf("Обещано: %d ₽, оплачено: %d ₽.", pledged, paid),
+	}
+
+	answered := make(map[int64]bool, len(pledges))
+	for _, p := range pledges {
+		answered[p.UserID] = true
+		status := "⏳ не оплачено"
+		if !p.PaidAt.IsZero() {
+			status = "✅ оплачено " + p.PaidAt.Format("02.01")
+		}
+		lines = append(lines, fmt.Sprintf("%s - %d ₽, %s", p.Username, p.Amount, status))
+	}
+
+	var silent []string
+	for _, p := range participants {
+		if !answered[p.ID] {
+			silent = append(silent, p.Username)
+		}
+	}
+	if len(silent) > 0 {
+		lines = append(lines, "Ещё не ответили: "+strings.Join(silent, ", "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// collectionCard описывает сбор для участника viewerID: организатора, итоги
+// и его собственный взнос. pledges может быть nil для только что открытого сбора.
+func collectionCard(c *models.Collection, pledges []models.CollectionPledge, viewerID int64) string {
+	organizer := "пока нет, нажмите «Я организую», чтобы собирать деньги"
+	if c.OrganizerID != 0 {
+		organizer = c.OrganizerName
+	}
+	pledged, paid := collection.Totals(pledges)
+
+	lines := []string{
+		fmt.Sprintf("Сбор №%d на подарок для %s ко дню рождения %s.", c.ID, c.Username, c.Birthday.Format("02.01")),
+		"Организатор: " + organizer + ".",
+		fmt.Sprintf("Взносов: %d, обещано %d ₽, оплачено %d ₽.", len(pledges), pledged, paid),
+	}
+
+	mine := "не указан, выберите сумму кнопкой или командой /pledge " + strconv.FormatInt(c.ID, 10) + " <сумма>"
+	if p := findPledge(pledges, viewerID); p != nil {
+		mine = fmt.Sprintf("%d ₽, не оплачен", p.Amount)
+		if !p.PaidAt.IsZero() {
+			mine = fmt.Sprintf("%d ₽, оплачен", p.Amount)
+		}
+	}
+	lines = append(lines, "Ваш взнос: "+mine+".")
+	return strings.Join(lines, "\n")
+}
+
+// collectionKeyboard возвращает кнопки сбора. После оплаты взнос не меняется,
+// поэтому кнопки сумм и оплаты скрываются.
+func collectionKeyboard(c *models.Collection, mine *models.CollectionPledge) tgbotapi.InlineKeyboardMarkup {
+	id := strconv.FormatInt(c.ID, 10)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if mine == nil || mine.PaidAt.IsZero() {
+		var amounts []tgbotapi.InlineKeyboardButton
+		for _, amount := range pledgeAmounts {
+			a := strconv.Itoa(amount)
+			amounts = append(amounts, tgbotapi.NewInlineKeyboardButtonData(a+" ₽", callbackData(callbackPledge, id, a)))
+		}
+		rows = append(rows, amounts)
+	}
+	if mine != nil && mine.PaidAt.IsZero() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Оплатил", callbackData(callbackPaid, id)),
+			tgbotapi.NewInlineKeyboardButtonData("Не участвую", callbackData(callbackWithdraw, id)),
+		))
+	}
+	if c.OrganizerID == 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Я организую", callbackData(callbackOrganize, id)),
+		))
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func findPledge(pledges []models.CollectionPledge, userID int64) *models.CollectionPledge {
+	for i := range pledges {
+		if pledges[i].UserID == userID {
+			return &pledges[i]
+		}
+	}
+	return nil
+}
+
+func (s *BotService) handleCollectionsCommand(message *tgbotapi.Message) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	collections, err := s.collections.UserCollections(user, time.Now())
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить сборы: "+err.Error()))
+		return
+	}
+	if len(collections) == 0 {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Открытых сборов нет."))
+		return
+	}
+
+	lines := []string{"Открытые сборы:"}
+	for _, c := range collections {
+		line := fmt.Sprintf("№%d - %s, %s", c.ID, c.Username, c.Birthday.Format("02.01"))
+		if c.OrganizerID == user.ID {
+			line += " (вы организатор)"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "Подробнее и кнопки взноса: /collection <номер>.")
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, strings.Join(lines, "\n")))
+}
+
+// handleCollectionCommandArgs показывает участнику карточку сбора с кнопками, а
+// организатору - ещё и сводку.
+func (s *BotService) handleCollectionCommandArgs(message *tgbotapi.Message, value string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+	id, _ := strconv.ParseInt(value, 10, 64)
+
+	c, pledges, err := s.collections.View(id, user)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+
+	if c.OrganizerID == user.ID {
+		summary, err := s.collectionSummary(id)
+		if err != nil {
+			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+			return
+		}
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, summary))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, collectionCard(c, pledges, user.ID))
+	msg.ReplyMarkup = collectionKeyboard(c, findPledge(pledges, user.ID))
+	s.bot.Send(msg)
+}
+
+func (s *BotService) handlePledgeCommandArgs(message *tgbotapi.Message, idValue, amountValue string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+	id, _ := strconv.ParseInt(idValue, 10, 64)
+	amount, _ := strconv.Atoi(amountValue)
+
+	c, err := s.collections.Pledge(id, user, amount)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	s.notifyOrganizer(c, user, fmt.Sprintf("%s обещает %d ₽ в сбор №%d для %s.", user.Username, amount, c.ID, c.Username))
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Взнос %d ₽ записан. Когда переведёте деньги организатору, нажмите «Оплатил» в /collection %d.", amount, c.ID)))
+}
+
+func (s *BotService) handlePledgeCallback(query *tgbotapi.CallbackQuery, args []string) {
+	if len(args) != 2 {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	amount, err := strconv.Atoi(args[1])
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+
+	s.collectionAction(query, args[:1], func(id int64, user *models.User) (string, error) {
+		c, err := s.collections.Pledge(id, user, amount)
+		if err != nil {
+			return "", err
+		}
+		s.notifyOrganizer(c, user, fmt.Sprintf("%s обещает %d ₽ в сбор №%d для %s.", user.Username, amount, c.ID, c.Username))
+		return fmt.Sprintf("Взнос %d ₽ записан.", amount), nil
+	})
+}
+
+func (s *BotService) handleWithdrawCallback(query *tgbotapi.CallbackQuery, args []string) {
+	s.collectionAction(query, args, func(id int64, user *models.User) (string, error) {
+		c, err := s.collections.Withdraw(id, user)
+		if err != nil {
+			return "", err
+		}
+		s.notifyOrganizer(c, user, fmt.Sprintf("%s отказался от участия в сборе №%d для %s.", user.Username, c.ID, c.Username))
+		return "Взнос отменён.", nil
+	})
+}
+
+func (s *BotService) handlePaidCallback(query *tgbotapi.CallbackQuery, args []string) {
+	s.collectionAction(query, args, func(id int64, user *models.User) (string, error) {
+		c, pledge, err := s.collections.MarkPaid(id, user)
+		if err != nil {
+			return "", err
+		}
+		s.notifyOrganizer(c, user, fmt.Sprintf("%s оплатил %d ₽ в сбор №%d для %s.", user.Username, pledge.Amount, c.ID, c.Username))
+		return "Оплата отмечена, спасибо!", nil
+	})
+}
+
+func (s *BotService) handleOrganizeCallback(query *tgbotapi.CallbackQuery, args []string) {
+	s.collectionAction(query, args, func(id int64, user *models.User) (string, error) {
+		if _, err := s.collections.Organize(id, user); err != nil {
+			return "", err
+		}
+		return "Вы организатор сбора. Сводка: /collection " + strconv.FormatInt(id, 10), nil
+	})
+}
+
+// collectionAction выполняет действие кнопки сбора с номером args[0] от имени
+// нажавшего пользователя и обновляет карточку сбора в сообщении.
+func (s *BotService) collectionAction(query *tgbotapi.CallbackQuery, args []string, action func(id int64, user *models.User) (string, error)) {
+	if len(args) != 1 {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+
+	user, err := s.userService.GetUserByTgID(query.From.ID)
+	if err != nil {
+		s.answerCallback(query, "Ошибка в поиске пользователя: "+err.Error())
+		return
+	}
+
+	text, err := action(id, user)
+	if err != nil {
+		s.answerCallback(query, "Ошибка: "+err.Error())
+		return
+	}
+	s.answerCallback(query, text)
+
+	c, pledges, err := s.collections.Get(id)
+	if err != nil {
+		logging.Logger.Printf("Ошибка в обновлении карточки сбора %d: %v", id, err)
+		return
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID,
+		collectionCard(c, pledges, user.ID), collectionKeyboard(c, findPledge(pledges, user.ID)))
+	s.bot.Send(edit)
+}
+
+// notifyOrganizer сообщает организатору сбора об изменении взноса участника.
+func (s *BotService) notifyOrganizer(c *models.Collection, actor *models.User, text string) {
+	if c.OrganizerID == 0 || c.OrganizerID == actor.ID {
+		return
+	}
+	organizer, err := s.userService.GetUserByID(c.OrganizerID)
+	if err != nil {
+		logging.Logger.Printf("Ошибка в поиске организатора сбора %d: %v", c.ID, err)
+		return
+	}
+	if err := s.SendMessage(organizer.TelegramID, text); err != nil {
+		logging.Logger.Printf("Ошибка в отправлении уведомления организатору %s: %v", organizer.Username, err)
+	}
+}
+
+func validateCollectionID(value string) error {
+	if id, err := strconv.ParseInt(value, 10, 64); err != nil || id <= 0 {
+		return errors.New(400, "номер сбора должен быть положительным числом")
+	}
+	return nil
+}
+
+func validateAmount(value string) error {
+	amount, err := strconv.Atoi(value)
+	if err != nil || amount <= 0 || amount > collection.MaxAmount {
+		return errors.New(400, fmt.Sprintf("сумма должна быть целым числом от 1 до %d", collection.MaxAmount))
+	}
+	return nil
+}
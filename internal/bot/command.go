@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"strings"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// command описывает команду бота для маршрутизации и справки /help.
+type command struct {
+	name        string
+	usage       string
+	description string
+	// public - команда доступна без входа в аккаунт.
+	public bool
+	// role - минимальная роль для команды, пустая - команда доступна всем вошедшим.
+	role    models.Role
+	handler func(message *tgbotapi.Message, args []string)
+}
+
+func (c *command) usageLine() string {
+	if c.usage == "" {
+		return c.name
+	}
+	return c.name + " " + c.usage
+}
+
+// commandRegistry хранит команды в порядке регистрации, чтобы /help выводил их в том же порядке.
+type commandRegistry struct {
+	byName map[string]*command
+	order  []*command
+}
+
+func newCommandRegistry() *commandRegistry {
+	return &commandRegistry{
+		byName: make(map[string]*command),
+	}
+}
+
+func (r *commandRegistry) add(c *command) {
+	r.byName[c.name] = c
+	r.order = append(r.order, c)
+}
+
+func (r *commandRegistry) get(name string) (*command, bool) {
+	c, ok := r.byName[name]
+	return c, ok
+}
+
+// parseCommand разбирает текст сообщения на команду и аргументы. Аргументы
+// разделяются пробелами, значение с пробелами можно взять в двойные или одинарные
+// кавычки, внутри двойных кавычек \" и \\ экранируют символы. Суффикс @имя_бота
+// у команды отбрасывается.
+func parseCommand(text string) (string, []string, error) {
+	tokens, err := splitArgs(text)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tokens) == 0 {
+		return "", nil, nil
+	}
+
+	name := tokens[0]
+	if strings.HasPrefix(name, "/") {
+		if at := strings.Index(name, "@"); at > 0 {
+			name = name[:at]
+		}
+	}
+	return name, tokens[1:], nil
+}
+
+func splitArgs(text string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			if quote == '"' && r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				r = runes[i]
+			}
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New(400, "не закрыта кавычка")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+func (s *BotService) handleHelpCommand(message *tgbotapi.Message, args []string) {
+	if len(args) > 0 {
+		name := args[0]
+		if !strings.HasPrefix(name, "/") {
+			name = "/" + name
+		}
+		c, ok := s.commands.get(name)
+		if !ok {
+			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Неизвестная команда "+name+"."))
+			return
+		}
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, c.usageLine()+"\n"+c.description))
+		return
+	}
+
+	loggedIn := s.isLoggedIn(message.Chat.ID)
+	role := models.RoleUser
+	if loggedIn {
+		role = s.currentRole(message.From.ID)
+	}
+	returnMessage := "Доступные команды:\n"
+	for _, c := range s.commands.order {
+		if !c.public && !loggedIn || c.role != "" && !role.AtLeast(c.role) {
+			continue
+		}
+		returnMessage += c.usageLine() + " - " + c.description + "\n"
+	}
+	returnMessage += "\nАргументы можно указать сразу после команды или ввести по запросу бота. Значения с пробелами берите в кавычки."
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, returnMessage))
+}
+
+// sendUsage сообщает об ошибке в аргументах и показывает правильный формат команды.
+func (s *BotService) sendUsage(message *tgbotapi.Message, name string, err error) {
+	text := "Неверный формат команды."
+	if err != nil {
+		text = "Ошибка: " + err.Error()
+	}
+	if c, ok := s.commands.get(name); ok {
+		text += "\nИспользование: " + c.usageLine()
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+}
@@ -0,0 +1,344 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/reminder"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dialogField - одно значение, которое многошаговая команда запрашивает у пользователя.
+type dialogField struct {
+	key      string
+	prompt   string
+	validate func(value string) error
+	// variadic - поле забирает все оставшиеся аргументы команды, например список дней.
+	variadic bool
+	// secret - значение нельзя оставлять в истории чата, например пароль:
+	// сообщение с ним бот удаляет, а само значение не сохраняется в базе данных.
+	secret bool
+}
+
+// dialogFlow описывает многошаговую команду: какие поля собрать по очереди
+// и что сделать с собранными значениями.
+type dialogFlow struct {
+	fields []dialogField
+	finish func(message *tgbotapi.Message, values map[string]string)
+}
+
+// dialogManager хранит состояние многошаговых команд в базе данных,
+// поэтому начатый диалог переживает перезапуск бота. Значения секретных полей
+// хранятся только в памяти: если бот перезапустился до конца диалога,
+// команду нужно повторить.
+type dialogManager struct {
+	repo    db.DialogRepository
+	timeout time.Duration
+	flows   map[string]dialogFlow
+
+	mu      sync.Mutex
+	secrets map[int64]dialogSecrets
+}
+
+// dialogSecrets - значения секретных полей незавершённого диалога чата.
+type dialogSecrets struct {
+	values    map[string]string
+	updatedAt time.Time
+}
+
+func newDialogManager(repo db.DialogRepository, timeout time.Duration) *dialogManager {
+	return &dialogManager{
+		repo:    repo,
+		timeout: timeout,
+		flows:   make(map[string]dialogFlow),
+		secrets: make(map[int64]dialogSecrets),
+	}
+}
+
+func (d *dialogManager) register(command string, flow dialogFlow) {
+	d.flows[command] = flow
+}
+
+// current возвращает активный диалог чата. Если диалог есть, но истёк или
+// потерял секретные значения при перезапуске бота, он удаляется и
+// возвращается expired = true.
+func (d *dialogManager) current(chatID int64) (state *models.DialogState, expired bool) {
+	state, err := d.repo.GetDialogState(chatID)
+	if err != nil {
+		return nil, false
+	}
+
+	flow, ok := d.flows[state.Command]
+	if !ok || d.timeout > 0 && time.Since(state.UpdatedAt) > d.timeout {
+		d.end(chatID)
+		return state, true
+	}
+
+	d.mu.Lock()
+	for key, value := range d.secrets[chatID].values {
+		state.Data[key] = value
+	}
+	d.mu.Unlock()
+
+	for _, field := range flow.fields[:min(state.Step, len(flow.fields))] {
+		if _, ok := state.Data[field.key]; !ok {
+			d.end(chatID)
+			return state, true
+		}
+	}
+	return state, false
+}
+
+// save сохраняет диалог в базе данных без значений секретных полей,
+// они остаются в памяти.
+func (d *dialogManager) save(state *models.DialogState) error {
+	state.UpdatedAt = time.Now()
+
+	stored := *state
+	stored.Data = make(map[string]string, len(state.Data))
+	secrets := make(map[string]string)
+	for _, field := range d.flows[state.Command].fields {
+		value, ok := state.Data[field.key]
+		switch {
+		case !ok:
+		case field.secret:
+			secrets[field.key] = value
+		default:
+			stored.Data[field.key] = value
+		}
+	}
+	if err := d.repo.SaveDialogState(&stored); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(secrets) > 0 {
+		d.secrets[state.ChatID] = dialogSecrets{values: secrets, updatedAt: state.UpdatedAt}
+	} else {
+		delete(d.secrets, state.ChatID)
+	}
+	return nil
+}
+
+func (d *dialogManager) end(chatID int64) {
+	d.mu.Lock()
+	delete(d.secrets, chatID)
+	d.mu.Unlock()
+
+	if err := d.repo.DeleteDialogState(chatID); err != nil {
+		logging.Logger.Printf("Ошибка в удалении состояния диалога %d: %v", chatID, err)
+	}
+}
+
+// runCleanup периодически удаляет истёкшие диалоги, пока не будет отменён ctx.
+func (d *dialogManager) runCleanup(ctx context.Context, interval time.Duration) {
+	if d.timeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := time.Now().Add(-d.timeout)
+			if _, err := d.repo.DeleteStaleDialogStates(before); err != nil {
+				logging.Logger.Printf("Ошибка в очистке диалогов: %v", err)
+			}
+			d.mu.Lock()
+			for chatID, secrets := range d.secrets {
+				if secrets.updatedAt.Before(before) {
+					delete(d.secrets, chatID)
+				}
+			}
+			d.mu.Unlock()
+		}
+	}
+}
+
+// startDialog выполняет многошаговую команду. Аргументы, указанные сразу после
+// команды, заполняют поля по порядку: если их хватает, команда выполняется сразу,
+// иначе бот запрашивает недостающие значения. intro, если не пустой,
+// отправляется перед первым вопросом.
+func (s *BotService) startDialog(message *tgbotapi.Message, command string, args []string, intro string) {
+	flow := s.dialogs.flows[command]
+	state := &models.DialogState{
+		ChatID:  message.Chat.ID,
+		Command: command,
+		Data:    make(map[string]string),
+	}
+
+	deleted := false
+	for state.Step < len(flow.fields) && len(args) > 0 {
+		field := flow.fields[state.Step]
+		if field.secret && !deleted {
+			s.deleteMessage(message)
+			deleted = true
+		}
+		value := args[0]
+		args = args[1:]
+		if field.variadic {
+			value = strings.Join(append([]string{value}, args...), " ")
+			args = nil
+		}
+
+		if field.validate != nil {
+			if err := field.validate(value); err != nil {
+				s.sendUsage(message, command, err)
+				return
+			}
+		}
+		state.Data[field.key] = value
+		state.Step++
+	}
+
+	if len(args) > 0 {
+		s.sendUsage(message, command, nil)
+		return
+	}
+
+	if state.Step == len(flow.fields) {
+		flow.finish(message, state.Data)
+		return
+	}
+
+	if err := s.dialogs.save(state); err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Не удалось начать команду: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	prompt := flow.fields[state.Step].prompt
+	if intro != "" && state.Step == 0 {
+		prompt = intro + "\n" + prompt
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, prompt+"\nДля отмены введите /cancel."))
+}
+
+// handleDialogInput принимает ответ на текущий вопрос диалога. Некорректный ответ
+// повторяет вопрос, после последнего ответа команда выполняется. Права на команду
+// проверяются заново при каждом ответе: сессию могли завершить или роль снять,
+// пока диалог ждал ответа.
+func (s *BotService) handleDialogInput(message *tgbotapi.Message, state *models.DialogState) {
+	flow := s.dialogs.flows[state.Command]
+	if state.Step >= len(flow.fields) {
+		s.dialogs.end(state.ChatID)
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Команда "+state.Command+" прервана. Повторите команду."))
+		return
+	}
+	field := flow.fields[state.Step]
+	value := strings.TrimSpace(message.Text)
+	if field.secret {
+		s.deleteMessage(message)
+	}
+
+	// Диалог называется по команде, например "/wishlist add" относится к /wishlist.
+	if cmd, ok := s.commands.get(strings.Fields(state.Command)[0]); ok && !s.checkAccess(message, cmd) {
+		s.dialogs.end(state.ChatID)
+		return
+	}
+
+	if field.validate != nil {
+		if err := field.validate(value); err != nil {
+			if err := s.dialogs.save(state); err != nil {
+				logging.Logger.Printf("Ошибка в сохранении состояния диалога %d: %v", state.ChatID, err)
+			}
+			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()+"\n"+field.prompt))
+			return
+		}
+	}
+
+	state.Data[field.key] = value
+	state.Step++
+
+	if state.Step < len(flow.fields) {
+		if err := s.dialogs.save(state); err != nil {
+			s.dialogs.end(state.ChatID)
+			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось продолжить команду: "+err.Error()))
+			return
+		}
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, flow.fields[state.Step].prompt))
+		return
+	}
+
+	s.dialogs.end(state.ChatID)
+	flow.finish(message, state.Data)
+}
+
+func (s *BotService) handleCancelCommand(message *tgbotapi.Message) {
+	state, _ := s.dialogs.current(message.Chat.ID)
+	if state == nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Нет активной команды для отмены."))
+		return
+	}
+
+	s.dialogs.end(message.Chat.ID)
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Команда "+state.Command+" отменена."))
+}
+
+func validateNotEmpty(value string) error {
+	if value == "" {
+		return errors.New(400, "значение не может быть пустым")
+	}
+	return nil
+}
+
+func validateSingleWord(value string) error {
+	if value == "" || strings.ContainsAny(value, " \t\n") {
+		return errors.New(400, "значение должно быть одним словом без пробелов")
+	}
+	return nil
+}
+
+func validateUsername(value string) error {
+	if err := validateSingleWord(value); err != nil {
+		return errors.New(400, "username должен быть одним словом без пробелов")
+	}
+	if strings.HasPrefix(value, "/") {
+		return errors.New(400, "username не может начинаться с /")
+	}
+	return nil
+}
+
+func validateDate(value string) error {
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return errors.New(400, "неверный формат даты, используйте YYYY-MM-DD")
+	}
+	return nil
+}
+
+func validateOptionalDate(value string) error {
+	if value == "-" {
+		return nil
+	}
+	return validateDate(value)
+}
+
+func validateClock(value string) error {
+	if _, err := time.Parse("15:04", value); err != nil {
+		return errors.New(400, "неверный формат времени, используйте HH:MM")
+	}
+	return nil
+}
+
+func validateOffsets(value string) error {
+	offsets, err := reminder.ParseOffsets(strings.Fields(value))
+	if err != nil {
+		return err
+	}
+	if len(offsets) == 0 {
+		return errors.New(400, "укажите хотя бы одно напоминание")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"BirthdayGreetings/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dispatcher обрабатывает обновления в нескольких воркерах. Все обновления
+// одного чата попадают в очередь одного и того же воркера, поэтому внутри
+// чата порядок сохраняется, а медленный запрос одного пользователя не
+// задерживает остальных.
+type dispatcher struct {
+	queues []chan tgbotapi.Update
+	handle func(update tgbotapi.Update)
+	wg     sync.WaitGroup
+}
+
+// newDispatcher запускает workers воркеров, у каждого очередь на queueSize обновлений.
+func newDispatcher(workers, queueSize int, handle func(update tgbotapi.Update)) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	d := &dispatcher{
+		queues: make([]chan tgbotapi.Update, workers),
+		handle: handle,
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+// dispatch ставит обновление в очередь воркера его чата. Если очередь
+// заполнена, dispatch ждёт освобождения места, замедляя получение обновлений.
+func (d *dispatcher) dispatch(update tgbotapi.Update) {
+	queue := d.queues[queueIndex(updateChatID(update), len(d.queues))]
+
+	select {
+	case queue <- update:
+	default:
+		logging.Logger.Printf("Очередь обработки обновлений заполнена, ожидание (update %d)", update.UpdateID)
+		queue <- update
+	}
+}
+
+// stop закрывает очереди и ждёт, пока воркеры обработают уже принятые обновления.
+func (d *dispatcher) stop() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
+
+func (d *dispatcher) work(queue <-chan tgbotapi.Update) {
+	defer d.wg.Done()
+
+	for update := range queue {
+		d.safeHandle(update)
+	}
+}
+
+func (d *dispatcher) safeHandle(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Logger.Printf("Паника при обработке update %d: %v\n%s", update.UpdateID, r, debug.Stack())
+		}
+	}()
+
+	d.handle(update)
+}
+
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+func queueIndex(chatID int64, n int) int {
+	if chatID < 0 {
+		chatID = -chatID
+	}
+	return int(chatID % int64(n))
+}
@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackUsersPage   = "users"
+	callbackSubscribe   = "sub"
+	callbackUnsubscribe = "unsub"
+)
+
+// usersPageSize - сколько пользователей показывается на одной странице /userslist.
+const usersPageSize = 5
+
+func (s *BotService) handleUsersListCommand(message *tgbotapi.Message) {
+	text, keyboard, err := s.usersPage(message.From.ID, 0)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "Ошибка в получении пользователей: "+err.Error())
+		s.bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	s.bot.Send(msg)
+}
+
+// usersPage возвращает текст и клавиатуру страницы page списка пользователей,
+// как его видит пользователь telegramID: без него самого и с кнопками,
+// отражающими его текущие подписки.
+func (s *BotService) usersPage(telegramID int64, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	currentUser, err := s.userService.GetUserByTgID(telegramID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	allUsers, err := s.userService.GetAllUsers()
+	if err != nil {
+		return "", nil, err
+	}
+
+	users := make([]*models.UserBirthLayout, 0, len(allUsers))
+	for _, user := range allUsers {
+		if user.ID != currentUser.ID {
+			users = append(users, user)
+		}
+	}
+
+	if len(users) == 0 {
+		return "Других пользователей пока нет.", nil, nil
+	}
+
+	subscriptions, err := s.subService.GetSubscriptions(currentUser.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	subscribed := make(map[int64]bool, len(subscriptions))
+	for _, sub := range subscriptions {
+		subscribed[sub.ID] = true
+	}
+
+	pages := (len(users) + usersPageSize - 1) / usersPageSize
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+	pageArg := strconv.Itoa(page)
+
+	text := fmt.Sprintf("Пользователи (страница %d из %d):\n", page+1, pages)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, user := range users[page*usersPageSize : min(len(users), (page+1)*usersPageSize)] {
+		date := "не указана"
+		if birthday.IsSet(user.Birthday) {
+			date = user.Birthday.Format("02.01.2006")
+		}
+		text += fmt.Sprintf("Пользователь: %s, Дата рождения: %s\n", user.Username, date)
+
+		userArg := strconv.FormatInt(user.ID, 10)
+		button := tgbotapi.NewInlineKeyboardButtonData("🔔 Подписаться на "+user.Username, callbackData(callbackSubscribe, userArg, pageArg))
+		if subscribed[user.ID] {
+			button = tgbotapi.NewInlineKeyboardButtonData("🔕 Отписаться от "+user.Username, callbackData(callbackUnsubscribe, userArg, pageArg))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+	}
+
+	if pages > 1 {
+		var nav []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", callbackData(callbackUsersPage, strconv.Itoa(page-1))))
+		}
+		if page < pages-1 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("Вперёд ▶️", callbackData(callbackUsersPage, strconv.Itoa(page+1))))
+		}
+		rows = append(rows, nav)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &keyboard, nil
+}
+
+// refreshUsersPage перерисовывает сообщение со списком пользователей после нажатия кнопки.
+func (s *BotService) refreshUsersPage(query *tgbotapi.CallbackQuery, page int) {
+	text, keyboard, err := s.usersPage(query.From.ID, page)
+	if err != nil {
+		s.answerCallback(query, "Ошибка в получении пользователей: "+err.Error())
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ReplyMarkup = keyboard
+	s.bot.Send(edit)
+}
+
+func (s *BotService) handleUsersPageCallback(query *tgbotapi.CallbackQuery, args []string) {
+	if len(args) != 1 {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	page, err := strconv.Atoi(args[0])
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+
+	s.answerCallback(query, "")
+	s.refreshUsersPage(query, page)
+}
+
+func (s *BotService) handleSubscribeCallback(query *tgbotapi.CallbackQuery, args []string) {
+	s.handleSubscriptionCallback(query, args, true)
+}
+
+func (s *BotService) handleUnsubscribeCallback(query *tgbotapi.CallbackQuery, args []string) {
+	s.handleSubscriptionCallback(query, args, false)
+}
+
+func (s *BotService) handleSubscriptionCallback(query *tgbotapi.CallbackQuery, args []string, subscribe bool) {
+	if len(args) != 2 {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	targetID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	page, err := strconv.Atoi(args[1])
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+
+	currentUser, err := s.userService.GetUserByTgID(query.From.ID)
+	if err != nil {
+		s.answerCallback(query, "Ошибка в поиске пользователя: "+err.Error())
+		return
+	}
+	if currentUser.ID == targetID {
+		s.answerCallback(query, "Вы не можете подписаться сами на себя")
+		return
+	}
+
+	isSubscribed, err := s.subService.IsSubscribed(currentUser.ID, targetID)
+	if err != nil {
+		s.answerCallback(query, "Ошибка в проверке подписки: "+err.Error())
+		return
+	}
+
+	switch {
+	case subscribe && !isSubscribed:
+		err = s.subService.SubscribeUser(currentUser.ID, targetID)
+	case !subscribe && isSubscribed:
+		err = s.subService.UnsubscribeUser(currentUser.ID, targetID)
+	}
+	if err != nil {
+		s.answerCallback(query, "Ошибка: "+err.Error())
+		return
+	}
+
+	if subscribe {
+		s.answerCallback(query, "Вы подписались.")
+	} else {
+		s.answerCallback(query, "Вы отписались.")
+	}
+	s.refreshUsersPage(query, page)
+}
@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/auth"
+	"BirthdayGreetings/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// registerPasswordlessDialogs описывает /login и /register для входа без пароля:
+// пользователя определяет Telegram аккаунт, PIN-код запрашивается, только если задан.
+func (s *BotService) registerPasswordlessDialogs() {
+	s.dialogs.register("/login", dialogFlow{
+		fields: []dialogField{
+			{key: "pin", prompt: "Введите PIN-код.", validate: auth.ValidatePIN, secret: true},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleTelegramLoginArgs(message, values["pin"])
+		},
+	})
+	s.dialogs.register("/register", dialogFlow{
+		fields: []dialogField{
+			{key: "username", prompt: "Придумайте username.", validate: validateUsername},
+			{key: "birthday", prompt: "Введите дату рождения в формате YYYY-MM-DD или - чтобы указать её позже.", validate: validateOptionalDate},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleRegisterCommandArgs(message, values["username"], "", values["birthday"])
+		},
+	})
+	s.dialogs.register("/setpin", dialogFlow{
+		fields: []dialogField{
+			{key: "pin", prompt: "Введите новый PIN-код из 4-8 цифр или - чтобы удалить PIN-код.", validate: validateOptionalPIN, secret: true},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleSetPINCommandArgs(message, values["pin"])
+		},
+	})
+}
+
+// loginHint подсказывает, как войти при текущем способе входа.
+func (s *BotService) loginHint() string {
+	if s.authService.Passwordless() {
+		return "Используйте команду /login."
+	}
+	return "Используйте команду /login username password."
+}
+
+// handleTelegramLoginCommand выполняет вход без пароля. PIN-код можно указать
+// сразу после команды, иначе бот запросит его, если он задан.
+func (s *BotService) handleTelegramLoginCommand(message *tgbotapi.Message, args []string) {
+	if len(args) > 0 {
+		s.startDialog(message, "/login", args, "")
+		return
+	}
+
+	s.handleTelegramLoginArgs(message, "")
+}
+
+// handleTelegramLoginArgs входит по Telegram аккаунту и PIN-коду. Если PIN-код
+// задан, но не указан, бот запрашивает его.
+func (s *BotService) handleTelegramLoginArgs(message *tgbotapi.Message, pin string) {
+	user, err := s.authService.AuthenticateTelegram(message.From.ID, pin)
+	if err == auth.ErrPINRequired {
+		s.startDialog(message, "/login", nil, "")
+		return
+	}
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка входа: "+err.Error()))
+		return
+	}
+
+	if err := s.sessions.Login(message.Chat.ID, user.ID); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка входа: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Вход успешный. Добро пожаловать, "+user.Username))
+}
+
+func (s *BotService) handleSetPINCommand(message *tgbotapi.Message, args []string) {
+	s.startDialog(message, "/setpin", args, "")
+}
+
+func (s *BotService) handleSetPINCommandArgs(message *tgbotapi.Message, pin string) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error()))
+		return
+	}
+
+	text := "PIN-код задан. Теперь при входе нужно будет указать его: /login <PIN>."
+	if pin == "-" {
+		pin = ""
+		text = "PIN-код удалён."
+	}
+	if err := s.authService.SetPIN(user, pin); err != nil {
+		text = "Ошибка: " + err.Error()
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+// deleteMessage удаляет сообщение пользователя, например с паролем, чтобы
+// оно не осталось в истории чата.
+func (s *BotService) deleteMessage(message *tgbotapi.Message) {
+	if _, err := s.bot.Request(tgbotapi.NewDeleteMessage(message.Chat.ID, message.MessageID)); err != nil {
+		logging.Logger.Printf("Ошибка в удалении сообщения %d в чате %d: %v", message.MessageID, message.Chat.ID, err)
+	}
+}
+
+func validateOptionalPIN(value string) error {
+	if value == "-" {
+		return nil
+	}
+	return auth.ValidatePIN(value)
+}
+
+// registerPasswordDialogs описывает смену и восстановление пароля.
+func (s *BotService) registerPasswordDialogs() {
+	s.dialogs.register("/changepassword", dialogFlow{
+		fields: []dialogField{
+			{key: "old", prompt: "Введите текущий пароль.", validate: validateNotEmpty, secret: true},
+			{key: "new", prompt: passwordPolicyPrompt, validate: validateNotEmpty, secret: true},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleChangePasswordCommandArgs(message, values["old"], values["new"])
+		},
+	})
+	s.dialogs.register("/recover", dialogFlow{
+		fields: []dialogField{
+			{key: "code", prompt: "Введите код восстановления.", validate: auth.ValidateRecoveryCode, secret: true},
+			{key: "password", prompt: passwordPolicyPrompt, validate: validateNotEmpty, secret: true},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleRecoverCommandArgs(message, values["code"], values["password"])
+		},
+	})
+}
+
+const passwordPolicyPrompt = "Введите новый пароль: не короче 8 символов, хотя бы одна буква и одна цифра."
+
+func (s *BotService) handleChangePasswordCommand(message *tgbotapi.Message, args []string) {
+	s.startDialog(message, "/changepassword", args, "")
+}
+
+// handleChangePasswordCommandArgs меняет пароль и завершает остальные сессии пользователя.
+func (s *BotService) handleChangePasswordCommandArgs(message *tgbotapi.Message, oldPassword, newPassword string) {
+	user, err := s.userService.GetUserByTgID(message.From.ID)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить пользователя: "+err.Error()))
+		return
+	}
+
+	if err := s.authService.ChangePassword(user, oldPassword, newPassword); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка смены пароля: "+err.Error()))
+		return
+	}
+
+	revoked, err := s.sessions.RevokeOthers(user.ID, message.Chat.ID)
+	if err != nil {
+		logging.Logger.Printf("Ошибка в завершении сессий пользователя %s: %v", user.Username, err)
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Пароль изменён. Завершено других сессий: %d.", revoked)))
+}
+
+// handleRecoverCommand без аргументов отправляет код восстановления в личные
+// сообщения Telegram аккаунта, к которому привязан пользователь, и запрашивает
+// код и новый пароль. С аргументами сразу задаёт новый пароль.
+func (s *BotService) handleRecoverCommand(message *tgbotapi.Message, args []string) {
+	if len(args) > 0 {
+		s.startDialog(message, "/recover", args, "")
+		return
+	}
+
+	user, code, err := s.authService.StartRecovery(message.From.ID)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка восстановления: "+err.Error()))
+		return
+	}
+
+	notice := fmt.Sprintf("Код восстановления пароля: %s\nКод действует %d мин. Если вы не запрашивали восстановление, просто проигнорируйте это сообщение.",
+		code, int(auth.RecoveryCodeTTL/time.Minute))
+	if err := s.SendMessage(user.TelegramID, notice); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось отправить код: "+err.Error()))
+		return
+	}
+	s.startDialog(message, "/recover", nil, "Код восстановления отправлен вам в личные сообщения.")
+}
+
+func (s *BotService) handleRecoverCommandArgs(message *tgbotapi.Message, code, password string) {
+	user, err := s.authService.Recover(message.From.ID, code, password)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка восстановления: "+err.Error()))
+		return
+	}
+
+	if _, err := s.sessions.RevokeAll(user.ID); err != nil {
+		logging.Logger.Printf("Ошибка в завершении сессий пользователя %s: %v", user.Username, err)
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Пароль изменён, все сессии завершены. Войдите с новым паролем командой /login."))
+}
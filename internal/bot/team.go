@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"BirthdayGreetings/internal/team"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// registerTeamCommands описывает команды для работы с командами (группами
+// пользователей). Название с пробелами берётся в кавычки.
+func (s *BotService) registerTeamCommands() {
+	s.commands.add(&command{name: "/teams", usage: "[название]", description: "Список команд или участники указанной команды.",
+		handler: s.handleTeamsCommand})
+	s.commands.add(&command{name: "/createteam", usage: "<название>", description: "Создать команду и стать её администратором.",
+		handler: s.dialogCommand("/createteam")})
+	s.commands.add(&command{name: "/jointeam", usage: "<название>", description: "Вступить в команду.",
+		handler: s.dialogCommand("/jointeam")})
+	s.commands.add(&command{name: "/leaveteam", usage: "<название>", description: "Выйти из команды.",
+		handler: s.dialogCommand("/leaveteam")})
+	s.commands.add(&command{name: "/teamadmin", usage: "<название> <username>", description: "Назначить участника администратором команды.",
+		handler: s.dialogCommand("/teamadmin")})
+	s.commands.add(&command{name: "/teamremove", usage: "<название> <username>", description: "Исключить участника из команды.",
+		handler: s.dialogCommand("/teamremove")})
+}
+
+func (s *BotService) registerTeamDialogs() {
+	name := dialogField{key: "team", prompt: "Введите название команды.", validate: team.ValidateName}
+	target := dialogField{key: "username", prompt: "Введите имя пользователя.", validate: validateUsername}
+
+	s.dialogs.register("/createteam", dialogFlow{
+		fields: []dialogField{name},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleCreateTeamCommandArgs(message, values["team"])
+		},
+	})
+	s.dialogs.register("/jointeam", dialogFlow{
+		fields: []dialogField{name},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleJoinTeamCommandArgs(message, values["team"])
+		},
+	})
+	s.dialogs.register("/leaveteam", dialogFlow{
+		fields: []dialogField{name},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleLeaveTeamCommandArgs(message, values["team"])
+		},
+	})
+	s.dialogs.register("/teamadmin", dialogFlow{
+		fields: []dialogField{name, target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleTeamMemberCommandArgs(message, values["team"], values["username"], true)
+		},
+	})
+	s.dialogs.register("/teamremove", dialogFlow{
+		fields: []dialogField{name, target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleTeamMemberCommandArgs(message, values["team"], values["username"], false)
+		},
+	})
+}
+
+// handleTeamsCommand без аргументов выводит все команды, отмечая те, в которых
+// состоит пользователь, а с названием - участников команды.
+func (s *BotService) handleTeamsCommand(message *tgbotapi.Message, args []string) {
+	if len(args) > 1 {
+		s.sendUsage(message, "/teams", nil)
+		return
+	}
+	if len(args) == 1 {
+		s.sendTeamMembers(message, args[0])
+		return
+	}
+
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	teams, err := s.teams.List()
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить команды: "+err.Error()))
+		return
+	}
+	if len(teams) == 0 {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Команд пока нет. Создайте первую командой /createteam <название>."))
+		return
+	}
+
+	mine, err := s.teams.UserTeams(user.ID)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить команды: "+err.Error()))
+		return
+	}
+	joined := make(map[int64]bool, len(mine))
+	for _, t := range mine {
+		joined[t.ID] = true
+	}
+
+	lines := []string{"Команды:"}
+	for _, t := range teams {
+		line := fmt.Sprintf("%s - участников: %d", t.Name, t.Members)
+		if joined[t.ID] {
+			line += " (вы участник)"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "Участники команды: /teams <название>. Вступить: /jointeam <название>.")
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, strings.Join(lines, "\n")))
+}
+
+func (s *BotService) sendTeamMembers(message *tgbotapi.Message, name string) {
+	t, members, err := s.teams.Get(name)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+
+	lines := []string{fmt.Sprintf("Команда %s, участников: %d", t.Name, len(members))}
+	for _, m := range members {
+		line := m.Username
+		if m.IsAdmin {
+			line += " (администратор)"
+		}
+		lines = append(lines, line)
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, strings.Join(lines, "\n")))
+}
+
+func (s *BotService) handleCreateTeamCommandArgs(message *tgbotapi.Message, name string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	t, err := s.teams.Create(user, name)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка создания команды: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Команда "+t.Name+" создана, вы её администратор. Другие пользователи могут вступить командой /jointeam."))
+}
+
+func (s *BotService) handleJoinTeamCommandArgs(message *tgbotapi.Message, name string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	t, err := s.teams.Join(user, name)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Вы вступили в команду "+t.Name+". Вас добавят в её канал поздравлений."))
+}
+
+func (s *BotService) handleLeaveTeamCommandArgs(message *tgbotapi.Message, name string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	if err := s.teams.Leave(user, name); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Вы вышли из команды "+name+"."))
+}
+
+// handleTeamMemberCommandArgs назначает участника администратором команды,
+// если grant, иначе исключает его из команды.
+func (s *BotService) handleTeamMemberCommandArgs(message *tgbotapi.Message, name, username string, grant bool) {
+	actor := s.commandUser(message)
+	if actor == nil {
+		return
+	}
+
+	var err error
+	var text string
+	if grant {
+		err = s.teams.GrantAdmin(actor, name, username)
+		text = username + " теперь администратор команды " + name + "."
+	} else {
+		err = s.teams.RemoveMember(actor, name, username)
+		text = username + " исключён из команды " + name + "."
+	}
+	if err != nil {
+		text = "Ошибка: " + err.Error()
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"BirthdayGreetings/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// serveWebhook регистрирует webhook в Telegram и принимает обновления по HTTP,
+// пока не будет вызван Stop. Каждый запрос ждёт места в очереди обработчика,
+// поэтому при перегрузке Telegram повторит доставку позже.
+func (s *BotService) serveWebhook(pool *dispatcher) error {
+	webhook := s.config.Webhook
+
+	config, err := tgbotapi.NewWebhook(webhook.Endpoint())
+	if err != nil {
+		return err
+	}
+	if _, err := s.bot.Request(config); err != nil {
+		return fmt.Errorf("не удалось зарегистрировать webhook: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(webhook.Path(), func(w http.ResponseWriter, r *http.Request) {
+		// Telegram присылает обновления только POST запросами.
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		update, err := s.bot.HandleUpdate(r)
+		if err != nil {
+			logging.Logger.Printf("Некорректный запрос webhook: %v", err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		pool.dispatch(*update)
+	})
+	server := &http.Server{Addr: webhook.Listen, Handler: mux}
+
+	errs := make(chan error, 1)
+	go func() {
+		if webhook.CertFile != "" {
+			errs <- server.ListenAndServeTLS(webhook.CertFile, webhook.KeyFile)
+		} else {
+			errs <- server.ListenAndServe()
+		}
+	}()
+	logging.Logger.Printf("Бот принимает обновления через webhook на %s", webhook.Listen)
+
+	var ctx context.Context
+	select {
+	case err := <-errs:
+		return fmt.Errorf("ошибка сервера webhook: %w", err)
+	case ctx = <-s.stop:
+	}
+
+	// Shutdown дожидается запросов, которые уже передают обновления в очередь,
+	// но не дольше, чем позволяет контекст Stop.
+	return server.Shutdown(ctx)
+}
+
+// poll получает обновления через long polling, пока не будет вызван Stop.
+func (s *BotService) poll(pool *dispatcher) error {
+	// Пока webhook зарегистрирован, Telegram не отдаёт обновления через getUpdates.
+	if _, err := s.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("не удалось удалить webhook: %w", err)
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+
+	updates := s.bot.GetUpdatesChan(u)
+
+	// Не ждём окончания текущего long polling запроса: полученные им обновления
+	// не подтверждены и придут снова после перезапуска.
+	for {
+		select {
+		case <-s.stop:
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			pool.dispatch(update)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/wish"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxMessageLength - предел длины сообщения Telegram. Более длинные
+// пожелания отправляются HTML-открыткой.
+const maxMessageLength = 4096
+
+// WishDeliverer доставляет пожелание, написанное в сам день рождения:
+// имениннику и в общий канал, как и ежедневная рассылка.
+type WishDeliverer interface {
+	DeliverWish(w models.Wish) error
+}
+
+// SetWishDeliverer задаёт доставку пожеланий в день рождения. Сервис
+// уведомлений создаётся после бота, поэтому передаётся отдельно.
+func (s *BotService) SetWishDeliverer(deliverer WishDeliverer) {
+	s.wishDeliverer = deliverer
+}
+
+func (s *BotService) registerWishCommands() {
+	s.commands.add(&command{name: "/wish", usage: "<username> <текст>", description: "Тайное пожелание ко дню рождения, именинник получит его в свой день.",
+		handler: s.dialogCommand("/wish")})
+	s.commands.add(&command{name: "/unwish", usage: "<username>", description: "Удалить своё пожелание пользователю.",
+		handler: s.dialogCommand("/unwish")})
+}
+
+func (s *BotService) registerWishDialogs() {
+	target := dialogField{key: "username", prompt: "Введите имя именинника.", validate: validateUsername}
+
+	// Текст пожелания бот удаляет из чата, чтобы именинник не увидел его
+	// раньше времени, если команда отправлена в общем чате.
+	s.dialogs.register("/wish", dialogFlow{
+		fields: []dialogField{
+			target,
+			{key: "text", prompt: "Напишите пожелание.", validate: validateNotEmpty, variadic: true, secret: true},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleWishCommandArgs(message, values["username"], values["text"])
+		},
+	})
+	s.dialogs.register("/unwish", dialogFlow{
+		fields: []dialogField{target},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleUnwishCommandArgs(message, values["username"])
+		},
+	})
+}
+
+func (s *BotService) handleWishCommandArgs(message *tgbotapi.Message, username, text string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	w, target, now, err := s.wishes.Add(user, username, text)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	if now {
+		if s.wishDeliverer == nil {
+			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: доставка пожеланий недоступна"))
+			return
+		}
+		if err := s.wishDeliverer.DeliverWish(*w); err != nil {
+			s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка в отправлении пожелания: "+err.Error()))
+			return
+		}
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "У "+target.Username+" сегодня день рождения, пожелание отправлено сразу."))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Пожелание сохранено. "+target.Username+" получит его "+w.Birthday.Format("02.01.2006")+
+		" вместе с пожеланиями других. Новое пожелание заменит это, удалить его можно командой /unwish."))
+}
+
+func (s *BotService) handleUnwishCommandArgs(message *tgbotapi.Message, username string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	text := "Пожелание для " + username + " удалено."
+	if err := s.wishes.Remove(user, username); err != nil {
+		text = "Ошибка: " + err.Error()
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+}
+
+// SendWishes отправляет пожелания имениннику username в чат chatID одним
+// сообщением, а если они в него не помещаются - HTML-открыткой.
+func (s *BotService) SendWishes(chatID int64, username string, date time.Time, wishes []models.Wish) error {
+	text := wish.Message(username, wishes)
+	if utf8.RuneCountInString(text) <= maxMessageLength {
+		return s.SendMessage(chatID, text)
+	}
+
+	card, err := wish.Card(username, date, wishes)
+	if err != nil {
+		return err
+	}
+	document := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  "pozdravlenie-" + username + ".html",
+		Bytes: card,
+	})
+	document.Caption = "🎉 С днём рождения, " + username + "! Пожелания - в открытке."
+	_, err = s.bot.Send(document)
+	return err
+}
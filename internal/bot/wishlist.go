@@ -0,0 +1,286 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackReserve = "wlreserve"
+	callbackRelease = "wlrelease"
+)
+
+// wishlistStartPrefix - начало параметра /start в ссылке на список желаний:
+// https://t.me/<бот>?start=wishlist_<username>.
+const wishlistStartPrefix = "wishlist_"
+
+// startParam - символы, допустимые в параметре /start. Telegram ограничивает его 64 символами.
+var startParam = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+func (s *BotService) registerWishlistCommands() {
+	s.commands.add(&command{name: "/wishlist", usage: "[list|add <подарок>|remove <номер>|@<username>]", description: "Ваш список желаний или список желаний пользователя, на которого вы подписаны.",
+		handler: s.handleWishlistCommand})
+}
+
+func (s *BotService) registerWishlistDialogs() {
+	s.dialogs.register("/wishlist add", dialogFlow{
+		fields: []dialogField{
+			{key: "title", prompt: "Какой подарок добавить в список желаний?", validate: validateNotEmpty, variadic: true},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleWishlistAddArgs(message, values["title"])
+		},
+	})
+	s.dialogs.register("/wishlist remove", dialogFlow{
+		fields: []dialogField{
+			{key: "id", prompt: "Введите номер подарка из /wishlist.", validate: validateItemID},
+		},
+		finish: func(message *tgbotapi.Message, values map[string]string) {
+			s.handleWishlistRemoveArgs(message, values["id"])
+		},
+	})
+}
+
+// WishlistLink возвращает ссылку, открывающую список желаний username в боте,
+// или пустую строку, если имя нельзя передать в ссылке.
+func (s *BotService) WishlistLink(username string) string {
+	param := wishlistStartPrefix + username
+	if !startParam.MatchString(param) {
+		return ""
+	}
+	return "https://t.me/" + s.self.UserName + "?start=" + param
+}
+
+// handleWishlistCommand разбирает подкоманды /wishlist. Чужой список
+// открывается по имени с @, чтобы имена add, remove и list не совпадали с
+// подкомандами.
+func (s *BotService) handleWishlistCommand(message *tgbotapi.Message, args []string) {
+	switch {
+	case len(args) == 0 || len(args) == 1 && args[0] == "list":
+		s.sendOwnWishlist(message)
+	case args[0] == "add":
+		s.startDialog(message, "/wishlist add", args[1:], "")
+	case args[0] == "remove":
+		s.startDialog(message, "/wishlist remove", args[1:], "")
+	case len(args) == 1 && strings.HasPrefix(args[0], "@"):
+		s.sendWishlist(message, strings.TrimPrefix(args[0], "@"))
+	default:
+		s.sendUsage(message, "/wishlist", nil)
+	}
+}
+
+// sendOwnWishlist показывает владельцу его список желаний. Брони владельцу не видны.
+func (s *BotService) sendOwnWishlist(message *tgbotapi.Message) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	items, err := s.wishlists.Own(user)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось получить список желаний: "+err.Error()))
+		return
+	}
+	if len(items) == 0 {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ваш список желаний пуст. Добавьте подарок командой /wishlist add <подарок>."))
+		return
+	}
+
+	lines := []string{"Ваш список желаний:"}
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("№%d %s", item.ID, item.Title))
+	}
+	lines = append(lines, "", "Добавить: /wishlist add <подарок>, удалить: /wishlist remove <номер>.")
+	if link := s.WishlistLink(user.Username); link != "" {
+		lines = append(lines, "Ссылка для друзей: "+link)
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, strings.Join(lines, "\n")))
+}
+
+// sendWishlist показывает список желаний username с кнопками брони. В списке
+// видны брони, поэтому он всегда отправляется в личные сообщения: в общем
+// чате его увидел бы владелец.
+func (s *BotService) sendWishlist(message *tgbotapi.Message, username string) {
+	viewer := s.commandUser(message)
+	if viewer == nil {
+		return
+	}
+	if username == viewer.Username {
+		s.sendOwnWishlist(message)
+		return
+	}
+
+	owner, items, err := s.wishlists.View(viewer, username)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+
+	text, keyboard := wishlistView(owner.Username, items, viewer.ID)
+	msg := tgbotapi.NewMessage(viewer.TelegramID, text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	if _, err := s.bot.Send(msg); err != nil {
+		logging.Logger.Printf("Ошибка в отправлении списка желаний пользователю %s: %v", viewer.Username, err)
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Не удалось отправить список желаний. Напишите боту в личные сообщения и повторите команду."))
+		return
+	}
+	if message.Chat.ID != viewer.TelegramID {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Список желаний "+owner.Username+" отправлен вам в личные сообщения."))
+	}
+}
+
+// wishlistView возвращает текст и кнопки списка желаний owner так, как его
+// видит подписчик viewerID: свободные подарки можно забронировать, свою
+// бронь - снять, чужие брони только отмечены.
+func wishlistView(owner string, items []models.WishlistItem, viewerID int64) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if len(items) == 0 {
+		return "Список желаний " + owner + " пуст.", nil
+	}
+
+	lines := []string{"Список желаний " + owner + ":"}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, item := range items {
+		number := strconv.Itoa(i + 1)
+		itemArg := strconv.FormatInt(item.ID, 10)
+		switch item.ReservedBy {
+		case 0:
+			lines = append(lines, number+". "+item.Title)
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🎁 Забронировать №"+number, callbackData(callbackReserve, itemArg))))
+		case viewerID:
+			lines = append(lines, number+". "+item.Title+" - забронировано вами")
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Снять бронь №"+number, callbackData(callbackRelease, itemArg))))
+		default:
+			lines = append(lines, number+". "+item.Title+" - забронировано ("+item.ReservedName+")")
+		}
+	}
+
+	text := strings.Join(lines, "\n") + "\n\n" + owner + " не видит, какие подарки забронированы."
+	if len(rows) == 0 {
+		return text, nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &keyboard
+}
+
+func (s *BotService) handleWishlistAddArgs(message *tgbotapi.Message, title string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+
+	if _, err := s.wishlists.Add(user, title); err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Подарок добавлен в список желаний. Посмотреть список: /wishlist."))
+}
+
+func (s *BotService) handleWishlistRemoveArgs(message *tgbotapi.Message, value string) {
+	user := s.commandUser(message)
+	if user == nil {
+		return
+	}
+	id, _ := strconv.ParseInt(value, 10, 64)
+
+	item, err := s.wishlists.Remove(user, id)
+	if err != nil {
+		s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Ошибка: "+err.Error()))
+		return
+	}
+	s.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Подарок «"+item.Title+"» удалён из списка желаний."))
+	s.notifyReserver(item, user.Username)
+}
+
+// notifyReserver предупреждает забронировавшего, что подарок удалён из
+// списка желаний owner и бронь снята.
+func (s *BotService) notifyReserver(item *models.WishlistItem, owner string) {
+	if item.ReservedBy == 0 {
+		return
+	}
+	reserver, err := s.userService.GetUserByID(item.ReservedBy)
+	if err != nil {
+		logging.Logger.Printf("Ошибка в поиске забронировавшего подарок %d: %v", item.ID, err)
+		return
+	}
+	text := "Подарок «" + item.Title + "», который вы забронировали, удалён из списка желаний " + owner + ". Бронь снята."
+	if err := s.SendMessage(reserver.TelegramID, text); err != nil {
+		logging.Logger.Printf("Ошибка в отправлении уведомления о брони пользователю %s: %v", reserver.Username, err)
+	}
+}
+
+func (s *BotService) handleReserveCallback(query *tgbotapi.CallbackQuery, args []string) {
+	s.handleReservationCallback(query, args, true)
+}
+
+func (s *BotService) handleReleaseCallback(query *tgbotapi.CallbackQuery, args []string) {
+	s.handleReservationCallback(query, args, false)
+}
+
+// handleReservationCallback бронирует подарок или снимает бронь и обновляет
+// список желаний в сообщении.
+func (s *BotService) handleReservationCallback(query *tgbotapi.CallbackQuery, args []string, reserve bool) {
+	if len(args) != 1 {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+	itemID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		s.answerCallback(query, "Неверные данные кнопки.")
+		return
+	}
+
+	viewer, err := s.userService.GetUserByTgID(query.From.ID)
+	if err != nil {
+		s.answerCallback(query, "Ошибка в поиске пользователя: "+err.Error())
+		return
+	}
+
+	var owner *models.UserBirthLayout
+	text := "Подарок забронирован."
+	if reserve {
+		owner, err = s.wishlists.Reserve(viewer, itemID)
+	} else {
+		owner, err = s.wishlists.Release(viewer, itemID)
+		text = "Бронь снята."
+	}
+	if err != nil {
+		s.answerCallback(query, "Ошибка: "+err.Error())
+		return
+	}
+	s.answerCallback(query, text)
+	// Список с бронями обновляется только в личных сообщениях, чтобы
+	// владелец не увидел их в общем чате.
+	if !query.Message.Chat.IsPrivate() {
+		return
+	}
+
+	_, items, err := s.wishlists.View(viewer, owner.Username)
+	if err != nil {
+		return
+	}
+	view, keyboard := wishlistView(owner.Username, items, viewer.ID)
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, view)
+	if keyboard != nil {
+		edit.ReplyMarkup = keyboard
+	}
+	s.bot.Send(edit)
+}
+
+func validateItemID(value string) error {
+	if n, err := strconv.ParseInt(value, 10, 64); err != nil || n < 1 {
+		return errors.New(400, "номер подарка должен быть положительным числом")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package channel
+
+import (
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/telegram"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KeyBirthdays - ключ общего канала поздравлений.
+const KeyBirthdays = "birthdays"
+
+// ChannelService создаёт Telegram каналы один раз, хранит их в базе данных
+// и поддерживает состав участников.
+type ChannelService struct {
+	repo   db.ChannelRepository
+	client *telegram.Client
+	botID  int64
+	// mu не даёт одновременно создать два канала с одним ключом,
+	// например из расписания и из /runnotifications.
+	mu sync.Mutex
+}
+
+func NewChannelService(repo db.ChannelRepository, client *telegram.Client, botID int64) *ChannelService {
+	return &ChannelService{
+		repo:   repo,
+		client: client,
+		botID:  botID,
+	}
+}
+
+// Ensure возвращает канал с ключом key, а если его ещё нет, создаёт канал
+// с названием title и сохраняет его. Канал сохраняется сразу после создания,
+// чтобы не создать второй, если назначить бота администратором не удалось:
+// это повторяется при следующих вызовах Ensure и Existing.
+func (s *ChannelService) Ensure(ctx context.Context, key, title, about string) (*models.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channel, err := s.repo.GetChannel(key)
+	if err != nil {
+		created, err := s.client.CreateChannel(ctx, title, about)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка в создании канала: %w", err)
+		}
+		channel = &models.Channel{
+			Key:        key,
+			ChannelID:  created.ID,
+			AccessHash: created.AccessHash,
+			CreatedAt:  time.Now(),
+		}
+		if err := s.repo.SaveChannel(channel); err != nil {
+			return nil, err
+		}
+		logging.Logger.Printf("Создан канал %s (%d)", key, channel.ChannelID)
+	}
+
+	if err := s.addBot(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// Existing возвращает уже созданный канал с ключом key, не создавая новый,
+// или nil, если канала ещё нет.
+func (s *ChannelService) Existing(ctx context.Context, key string) (*models.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channel, err := s.repo.GetChannel(key)
+	if err != nil {
+		if e, ok := err.(*errors.CustomError); ok && e.Code == 404 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := s.addBot(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// addBot назначает бота администратором канала, если это ещё не сделано.
+func (s *ChannelService) addBot(ctx context.Context, channel *models.Channel) error {
+	if channel.BotAdded {
+		return nil
+	}
+	if err := s.client.AddBotToChannel(ctx, telegram.InputChannel(channel.ChannelID, channel.AccessHash), s.botID); err != nil {
+		return fmt.Errorf("ошибка в добавлении бота в канал %s: %w", channel.Key, err)
+	}
+	if err := s.repo.SetChannelBotAdded(channel.Key); err != nil {
+		return err
+	}
+	channel.BotAdded = true
+	return nil
+}
+
+// Sync приводит состав канала к telegramIDs: приглашает тех, кого в канале
+// ещё нет, и исключает тех, кого бот добавлял раньше, но кого больше нет
+// в списке, например удалённых пользователей. Ошибки с отдельными
+// пользователями логируются и не прерывают синхронизацию.
+func (s *ChannelService) Sync(ctx context.Context, channel *models.Channel, telegramIDs []int64) error {
+	members, err := s.repo.GetChannelMembers(channel.Key)
+	if err != nil {
+		return err
+	}
+
+	// Создатель канала уже в нём, пригласить или исключить его нельзя.
+	selfID, err := s.client.SelfID(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка в получении аккаунта Telegram: %w", err)
+	}
+
+	wanted := make(map[int64]bool, len(telegramIDs))
+	for _, id := range telegramIDs {
+		if id != selfID {
+			wanted[id] = true
+		}
+	}
+	current := make(map[int64]bool, len(members))
+	for _, id := range members {
+		current[id] = true
+	}
+
+	input := telegram.InputChannel(channel.ChannelID, channel.AccessHash)
+	invited, removed := 0, 0
+	for id := range wanted {
+		if current[id] {
+			continue
+		}
+		if err := s.client.InviteToChannel(ctx, input, id); err != nil {
+			logging.Logger.Printf("Ошибка в добавлении пользователя %d в канал %s: %v", id, channel.Key, err)
+			continue
+		}
+		if err := s.repo.AddChannelMember(channel.Key, id); err != nil {
+			logging.Logger.Printf("Ошибка в сохранении участника %d канала %s: %v", id, channel.Key, err)
+			continue
+		}
+		invited++
+	}
+
+	for id := range current {
+		if wanted[id] {
+			continue
+		}
+		if err := s.client.RemoveFromChannel(ctx, input, id); err != nil {
+			logging.Logger.Printf("Ошибка в исключении пользователя %d из канала %s: %v", id, channel.Key, err)
+			continue
+		}
+		if err := s.repo.RemoveChannelMember(channel.Key, id); err != nil {
+			logging.Logger.Printf("Ошибка в удалении участника %d канала %s: %v", id, channel.Key, err)
+			continue
+		}
+		removed++
+	}
+
+	if invited > 0 || removed > 0 {
+		logging.Logger.Printf("Канал %s: приглашено %d, исключено %d", channel.Key, invited, removed)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package collection
+
+import (
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/subscription"
+	"fmt"
+	"time"
+)
+
+// MaxAmount ограничивает сумму одного взноса.
+const MaxAmount = 1000000
+
+// CollectionService ведёт сборы денег на подарки. Сбор открывается за
+// несколько дней до дня рождения, участвуют в нём подписчики именинника.
+// Каждый участник обещает сумму и потом подтверждает оплату, а один из
+// участников вызывается быть организатором и получает сводку.
+type CollectionService struct {
+	repo       db.CollectionRepository
+	subService *subscription.SubscriptionService
+	daysBefore int
+}
+
+// NewCollectionService создаёт сервис сборов. daysBefore - за сколько дней до
+// дня рождения открывается сбор, 0 отключает сборы.
+func NewCollectionService(repo db.CollectionRepository, subService *subscription.SubscriptionService, daysBefore int) *CollectionService {
+	return &CollectionService{
+		repo:       repo,
+		subService: subService,
+		daysBefore: daysBefore,
+	}
+}
+
+// DaysBefore возвращает, за сколько дней до дня рождения открывается сбор.
+func (s *CollectionService) DaysBefore() int {
+	return s.daysBefore
+}
+
+// Open открывает сбор ко дню рождения user, который празднуется date, и
+// возвращает его участников. Если сбор уже открыт, возвращает nil.
+func (s *CollectionService) Open(user models.UserBirthLayout, date time.Time) (*models.Collection, []models.UserBirthLayout, error) {
+	c := &models.Collection{UserID: user.ID, Username: user.Username, Birthday: date}
+	created, err := s.repo.CreateCollection(c)
+	if err != nil || !created {
+		return nil, nil, err
+	}
+
+	participants, err := s.Participants(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, participants, nil
+}
+
+// Participants возвращает участников сбора: незаблокированных подписчиков
+// именинника, кроме него самого.
+func (s *CollectionService) Participants(c *models.Collection) ([]models.UserBirthLayout, error) {
+	subscribers, err := s.subService.GetSubscribersOf(c.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := make([]models.UserBirthLayout, 0, len(subscribers))
+	for _, sub := range subscribers {
+		if sub.ID != c.UserID && !sub.Banned {
+			participants = append(participants, sub)
+		}
+	}
+	return participants, nil
+}
+
+// Get возвращает сбор и взносы его участников.
+func (s *CollectionService) Get(id int64) (*models.Collection, []models.CollectionPledge, error) {
+	c, err := s.repo.GetCollection(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	pledges, err := s.repo.GetPledges(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, pledges, nil
+}
+
+// View возвращает сбор и взносы, если user - его участник или организатор.
+// Остальным, в том числе имениннику, сбор не виден, как если бы его не было.
+func (s *CollectionService) View(id int64, user *models.User) (*models.Collection, []models.CollectionPledge, error) {
+	c, pledges, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.OrganizerID != user.ID && s.checkParticipant(c, user) != nil {
+		return nil, nil, errors.New(404, "сбор не найден")
+	}
+	return c, pledges, nil
+}
+
+// Active возвращает сборы к ещё не прошедшим дням рождения.
+func (s *CollectionService) Active(today time.Time) ([]models.Collection, error) {
+	return s.repo.GetCollections(today)
+}
+
+// UserCollections возвращает активные сборы, в которых user участвует или которые организует.
+func (s *CollectionService) UserCollections(user *models.User, today time.Time) ([]models.Collection, error) {
+	collections, err := s.repo.GetCollections(today)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.Collection
+	for _, c := range collections {
+		if c.OrganizerID == user.ID {
+			result = append(result, c)
+			continue
+		}
+		if err := s.checkParticipant(&c, user); err == nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// Pledge записывает, сколько user обещает сдать на подарок. После
+// подтверждения оплаты сумму изменить нельзя.
+func (s *CollectionService) Pledge(id int64, user *models.User, amount int) (*models.Collection, error) {
+	if amount <= 0 || amount > MaxAmount {
+		return nil, errors.New(400, fmt.Sprintf("сумма взноса должна быть от 1 до %d", MaxAmount))
+	}
+	c, pledge, err := s.participantPledge(id, user)
+	if err != nil {
+		return nil, err
+	}
+	if pledge != nil && !pledge.PaidAt.IsZero() {
+		return nil, errors.New(400, "взнос уже оплачен, изменить сумму нельзя")
+	}
+
+	err = s.repo.SavePledge(&models.CollectionPledge{
+		CollectionID: id,
+		UserID:       user.ID,
+		Amount:       amount,
+		UpdatedAt:    time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Withdraw отменяет неоплаченный взнос user.
+func (s *CollectionService) Withdraw(id int64, user *models.User) (*models.Collection, error) {
+	c, pledge, err := s.participantPledge(id, user)
+	if err != nil {
+		return nil, err
+	}
+	if pledge == nil {
+		return nil, errors.New(400, "вы ещё не указали сумму взноса")
+	}
+	if !pledge.PaidAt.IsZero() {
+		return nil, errors.New(400, "взнос уже оплачен, отменить его нельзя")
+	}
+
+	if err := s.repo.DeletePledge(id, user.ID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// MarkPaid отмечает, что user сдал обещанную сумму, и возвращает сбор и взнос.
+func (s *CollectionService) MarkPaid(id int64, user *models.User) (*models.Collection, *models.CollectionPledge, error) {
+	c, pledge, err := s.participantPledge(id, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pledge == nil {
+		return nil, nil, errors.New(400, "сначала выберите сумму взноса")
+	}
+	if !pledge.PaidAt.IsZero() {
+		return nil, nil, errors.New(400, "оплата уже отмечена")
+	}
+
+	pledge.PaidAt = time.Now()
+	if err := s.repo.MarkPledgePaid(id, user.ID, pledge.PaidAt); err != nil {
+		return nil, nil, err
+	}
+	return c, pledge, nil
+}
+
+// Organize назначает user организатором сбора, если организатора ещё нет.
+func (s *CollectionService) Organize(id int64, user *models.User) (*models.Collection, error) {
+	c, err := s.repo.GetCollection(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOpen(c); err != nil {
+		return nil, err
+	}
+	if err := s.checkParticipant(c, user); err != nil {
+		return nil, err
+	}
+	if c.OrganizerID != 0 {
+		return nil, errors.New(409, "у сбора уже есть организатор: "+c.OrganizerName)
+	}
+
+	if err := s.repo.SetCollectionOrganizer(id, user.ID); err != nil {
+		return nil, err
+	}
+	c.OrganizerID = user.ID
+	c.OrganizerName = user.Username
+	return c, nil
+}
+
+// participantPledge проверяет, что user может участвовать в открытом сборе id,
+// и возвращает сбор и взнос user или nil, если взноса ещё нет.
+func (s *CollectionService) participantPledge(id int64, user *models.User) (*models.Collection, *models.CollectionPledge, error) {
+	c, pledges, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkOpen(c); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkParticipant(c, user); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range pledges {
+		if pledges[i].UserID == user.ID {
+			return c, &pledges[i], nil
+		}
+	}
+	return c, nil, nil
+}
+
+func (s *CollectionService) checkOpen(c *models.Collection) error {
+	if c.Birthday.Format("2006-01-02") < time.Now().Format("2006-01-02") {
+		return errors.New(400, "сбор закрыт: день рождения уже прошёл")
+	}
+	return nil
+}
+
+func (s *CollectionService) checkParticipant(c *models.Collection, user *models.User) error {
+	if c.UserID == user.ID {
+		return errors.New(403, "это сбор на подарок вам, участвовать в нём нельзя")
+	}
+	subscribed, err := s.subService.IsSubscribed(user.ID, c.UserID)
+	if err != nil {
+		return err
+	}
+	if !subscribed {
+		return errors.New(403, "в сборе участвуют только подписчики "+c.Username)
+	}
+	return nil
+}
+
+// Totals возвращает обещанную и уже оплаченную сумму взносов.
+func Totals(pledges []models.CollectionPledge) (pledged, paid int) {
+	for _, p := range pledges {
+		pledged += p.Amount
+		if !p.PaidAt.IsZero() {
+			paid += p.Amount
+		}
+	}
+	return pledged, paid
+}
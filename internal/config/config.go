@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"BirthdayGreetings/internal/birthday"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ModePolling - бот сам запрашивает обновления у Telegram (long polling).
+	ModePolling = "polling"
+	// ModeWebhook - Telegram отправляет обновления на HTTP-адрес бота.
+	ModeWebhook = "webhook"
+
+	// LoginPassword - вход по имени пользователя и паролю.
+	LoginPassword = "password"
+	// LoginTelegram - вход без пароля: пользователя определяет Telegram аккаунт,
+	// с которого пришло сообщение, и, если пользователь его задал, PIN-код.
+	LoginTelegram = "telegram"
+
+	// defaultFile - файл настроек, который читается, если он есть и CONFIG_FILE не задан.
+	defaultFile = "config.yaml"
+)
+
+// Config - все настройки приложения.
+type Config struct {
+	Postgres        Postgres            `yaml:"postgres"`
+	Telegram        Telegram            `yaml:"telegram"`
+	Bot             Bot                 `yaml:"bot"`
+	Session         Session             `yaml:"session"`
+	Login           Login               `yaml:"login"`
+	Collection      Collection          `yaml:"collection"`
+	LeapDayPolicy   birthday.LeapPolicy `yaml:"leap_day_policy"`
+	ShutdownTimeout time.Duration       `yaml:"shutdown_timeout"`
+}
+
+// Postgres - подключение к базе данных.
+type Postgres struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Database string `yaml:"database"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+// Telegram - пользовательский аккаунт, от имени которого создаются каналы.
+type Telegram struct {
+	AppID       int    `yaml:"app_id"`
+	AppHash     string `yaml:"app_hash"`
+	PhoneNumber string `yaml:"phone_number"`
+	// Password - пароль двухфакторной аутентификации, если она включена.
+	Password string `yaml:"password"`
+}
+
+// Bot - настройки Telegram бота.
+type Bot struct {
+	Token   string `yaml:"token"`
+	AdminID int64  `yaml:"admin_id"`
+	// DialogTimeout - сколько ждать ответа в многошаговой команде.
+	DialogTimeout time.Duration `yaml:"dialog_timeout"`
+	// Workers - количество параллельных обработчиков обновлений.
+	Workers int `yaml:"workers"`
+	// QueueSize - размер очереди обновлений одного обработчика.
+	QueueSize int `yaml:"queue_size"`
+	// Mode - способ получения обновлений: ModePolling или ModeWebhook.
+	Mode    string  `yaml:"mode"`
+	Webhook Webhook `yaml:"webhook"`
+}
+
+// Webhook - настройки приёма обновлений через webhook.
+type Webhook struct {
+	// URL - публичный адрес бота без секретного пути, например https://bot.example.com/telegram.
+	URL string `yaml:"url"`
+	// Listen - адрес, на котором бот принимает запросы, например :8443.
+	Listen string `yaml:"listen"`
+	// Secret - секретный сегмент пути: запросы на другие пути отклоняются.
+	Secret string `yaml:"secret"`
+	// CertFile и KeyFile включают TLS. Без них бот слушает обычный HTTP,
+	// например за ingress, который сам терминирует TLS.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// Session - время жизни сессий пользователей.
+type Session struct {
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	AbsoluteTimeout time.Duration `yaml:"absolute_timeout"`
+}
+
+// Login - способ входа и защита от подбора пароля.
+type Login struct {
+	// Mode - способ входа: LoginPassword или LoginTelegram.
+	Mode string `yaml:"mode"`
+	// MaxAttempts - сколько неудачных попыток подряд допускается до блокировки.
+	MaxAttempts int `yaml:"max_attempts"`
+	// LockoutBase - первая блокировка; каждая следующая неудача удваивает её.
+	LockoutBase time.Duration `yaml:"lockout_base"`
+	// LockoutMax - самая долгая блокировка. Неудачи старше неё забываются.
+	LockoutMax time.Duration `yaml:"lockout_max"`
+}
+
+// Collection - сборы денег на подарки.
+type Collection struct {
+	// DaysBefore - за сколько дней до дня рождения открывается сбор, 0 отключает сборы.
+	DaysBefore int `yaml:"days_before"`
+}
+
+// Default возвращает настройки по умолчанию.
+func Default() *Config {
+	return &Config{
+		Postgres: Postgres{
+			Port:    "5432",
+			SSLMode: "disable",
+		},
+		Bot: Bot{
+			DialogTimeout: 15 * time.Minute,
+			Workers:       8,
+			QueueSize:     100,
+			Mode:          ModePolling,
+		},
+		Session: Session{
+			IdleTimeout:     30 * 24 * time.Hour,
+			AbsoluteTimeout: 90 * 24 * time.Hour,
+		},
+		Login: Login{
+			Mode:        LoginPassword,
+			MaxAttempts: 5,
+			LockoutBase: time.Minute,
+			LockoutMax:  24 * time.Hour,
+		},
+		Collection: Collection{
+			DaysBefore: 7,
+		},
+		LeapDayPolicy:   birthday.LeapFeb28,
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// Load собирает настройки из нескольких источников, каждый следующий
+// переопределяет предыдущий: значения по умолчанию, YAML-файл (CONFIG_FILE или
+// config.yaml, если он есть), файл .env и переменные окружения. Отсутствие
+// .env и config.yaml ошибкой не считается. Load проверяет только формат
+// значений, полноту настроек проверяет Validate.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("ошибка чтения .env: %w", err)
+	}
+
+	cfg := Default()
+
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit || path == "" {
+		path = defaultFile
+	}
+	if err := cfg.readFile(path); err != nil {
+		if explicit || !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, v := range cfg.envVars() {
+		value, ok := os.LookupEnv(v.name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := v.set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("ошибка чтения %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate проверяет все настройки и возвращает сразу все найденные ошибки.
+func (c *Config) Validate() error {
+	errs := []error{
+		c.Postgres.Validate(),
+		c.Telegram.Validate(),
+		c.Bot.Validate(),
+		c.Session.Validate(),
+		c.Login.Validate(),
+		c.Collection.Validate(),
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT должен быть больше нуля"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate проверяет настройки подключения к базе данных.
+func (p Postgres) Validate() error {
+	required := []struct{ name, value string }{
+		{"POSTGRES_HOST", p.Host},
+		{"POSTGRES_PORT", p.Port},
+		{"POSTGRES_USER", p.User},
+		{"POSTGRES_DB", p.Database},
+	}
+
+	var errs []error
+	for _, r := range required {
+		if r.value == "" {
+			errs = append(errs, fmt.Errorf("%s не задан", r.name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DSN возвращает строку подключения к базе данных.
+func (p Postgres) DSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(p.User, p.Password),
+		Host:     p.Host + ":" + p.Port,
+		Path:     p.Database,
+		RawQuery: url.Values{"sslmode": {p.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// Validate проверяет настройки аккаунта Telegram.
+func (t Telegram) Validate() error {
+	var errs []error
+	if t.AppID <= 0 {
+		errs = append(errs, fmt.Errorf("TELEGRAM_APP_ID не задан"))
+	}
+	if t.AppHash == "" {
+		errs = append(errs, fmt.Errorf("TELEGRAM_APP_HASH не задан"))
+	}
+	if t.PhoneNumber == "" {
+		errs = append(errs, fmt.Errorf("TELEGRAM_PHONE_NUMBER не задан"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate проверяет настройки бота и, в режиме webhook, настройки webhook.
+func (b Bot) Validate() error {
+	var errs []error
+	if b.Token == "" {
+		errs = append(errs, fmt.Errorf("TELEGRAM_BOT_TOKEN не задан"))
+	}
+	if b.AdminID == 0 {
+		errs = append(errs, fmt.Errorf("ADMIN_ID не задан"))
+	}
+	if b.DialogTimeout < 0 {
+		errs = append(errs, fmt.Errorf("DIALOG_TIMEOUT не может быть отрицательным"))
+	}
+	if b.Workers < 1 {
+		errs = append(errs, fmt.Errorf("BOT_WORKERS должен быть не меньше 1"))
+	}
+	if b.QueueSize < 1 {
+		errs = append(errs, fmt.Errorf("BOT_QUEUE_SIZE должен быть не меньше 1"))
+	}
+
+	switch b.Mode {
+	case ModePolling:
+	case ModeWebhook:
+		errs = append(errs, b.Webhook.Validate())
+	default:
+		errs = append(errs, fmt.Errorf("BOT_MODE: неизвестный режим %q, используйте %s или %s", b.Mode, ModePolling, ModeWebhook))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate проверяет, что настроек достаточно для запуска webhook.
+func (w Webhook) Validate() error {
+	var errs []error
+	u, err := url.Parse(w.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("WEBHOOK_URL должен быть абсолютным https URL, получено %q", w.URL))
+	} else if u.RawQuery != "" || u.Fragment != "" {
+		errs = append(errs, fmt.Errorf("WEBHOOK_URL не должен содержать параметры запроса и фрагмент, получено %q", w.URL))
+	}
+	if w.Listen == "" {
+		errs = append(errs, fmt.Errorf("WEBHOOK_LISTEN не задан"))
+	}
+	if w.Secret == "" || strings.ContainsAny(w.Secret, "/?#% ") {
+		errs = append(errs, fmt.Errorf("WEBHOOK_SECRET должен быть непустым и не содержать / ? # %% и пробелов"))
+	}
+	if (w.CertFile == "") != (w.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("для TLS нужно указать и WEBHOOK_CERT_FILE, и WEBHOOK_KEY_FILE"))
+	}
+	return errors.Join(errs...)
+}
+
+// Path возвращает путь, на который Telegram отправляет обновления: путь из URL,
+// например /telegram, и секретный сегмент.
+func (w Webhook) Path() string {
+	var prefix string
+	if u, err := url.Parse(w.URL); err == nil {
+		prefix = strings.TrimSuffix(u.Path, "/")
+	}
+	return prefix + "/" + w.Secret
+}
+
+// Endpoint возвращает полный адрес, который регистрируется в Telegram.
+func (w Webhook) Endpoint() string {
+	return strings.TrimSuffix(w.URL, "/") + "/" + w.Secret
+}
+
+// Validate проверяет время жизни сессий. Нулевое значение отключает ограничение.
+func (s Session) Validate() error {
+	var errs []error
+	if s.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("SESSION_IDLE_TIMEOUT не может быть отрицательным"))
+	}
+	if s.AbsoluteTimeout < 0 {
+		errs = append(errs, fmt.Errorf("SESSION_ABSOLUTE_TIMEOUT не может быть отрицательным"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate проверяет способ входа и настройки блокировки входа.
+func (l Login) Validate() error {
+	var errs []error
+	if l.Mode != LoginPassword && l.Mode != LoginTelegram {
+		errs = append(errs, fmt.Errorf("LOGIN_MODE: неизвестный способ входа %q, используйте %s или %s", l.Mode, LoginPassword, LoginTelegram))
+	}
+	if l.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("LOGIN_MAX_ATTEMPTS должен быть не меньше 1"))
+	}
+	if l.LockoutBase <= 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_LOCKOUT_BASE должен быть больше нуля"))
+	}
+	if l.LockoutMax < l.LockoutBase {
+		errs = append(errs, fmt.Errorf("LOGIN_LOCKOUT_MAX не может быть меньше LOGIN_LOCKOUT_BASE"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate проверяет настройки сборов на подарки.
+func (c Collection) Validate() error {
+	if c.DaysBefore < 0 || c.DaysBefore > 365 {
+		return fmt.Errorf("COLLECTION_DAYS_BEFORE должен быть от 0 до 365")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// envVar связывает переменную окружения с полем настроек.
+type envVar struct {
+	name string
+	set  func(value string) error
+}
+
+func (c *Config) envVars() []envVar {
+	return []envVar{
+		{"POSTGRES_HOST", stringVar(&c.Postgres.Host)},
+		{"POSTGRES_PORT", stringVar(&c.Postgres.Port)},
+		{"POSTGRES_USER", stringVar(&c.Postgres.User)},
+		{"POSTGRES_PASSWORD", stringVar(&c.Postgres.Password)},
+		{"POSTGRES_DB", stringVar(&c.Postgres.Database)},
+		{"POSTGRES_SSLMODE", stringVar(&c.Postgres.SSLMode)},
+
+		{"TELEGRAM_APP_ID", intVar(&c.Telegram.AppID)},
+		{"TELEGRAM_APP_HASH", stringVar(&c.Telegram.AppHash)},
+		{"TELEGRAM_PHONE_NUMBER", stringVar(&c.Telegram.PhoneNumber)},
+		{"TELEGRAM_PASSWORD", stringVar(&c.Telegram.Password)},
+
+		{"TELEGRAM_BOT_TOKEN", stringVar(&c.Bot.Token)},
+		{"ADMIN_ID", int64Var(&c.Bot.AdminID)},
+		{"DIALOG_TIMEOUT", durationVar(&c.Bot.DialogTimeout)},
+		{"BOT_WORKERS", intVar(&c.Bot.Workers)},
+		{"BOT_QUEUE_SIZE", intVar(&c.Bot.QueueSize)},
+		{"BOT_MODE", stringVar(&c.Bot.Mode)},
+		{"WEBHOOK_URL", stringVar(&c.Bot.Webhook.URL)},
+		{"WEBHOOK_LISTEN", stringVar(&c.Bot.Webhook.Listen)},
+		{"WEBHOOK_SECRET", stringVar(&c.Bot.Webhook.Secret)},
+		{"WEBHOOK_CERT_FILE", stringVar(&c.Bot.Webhook.CertFile)},
+		{"WEBHOOK_KEY_FILE", stringVar(&c.Bot.Webhook.KeyFile)},
+
+		{"SESSION_IDLE_TIMEOUT", durationVar(&c.Session.IdleTimeout)},
+		{"SESSION_ABSOLUTE_TIMEOUT", durationVar(&c.Session.AbsoluteTimeout)},
+
+		{"LOGIN_MODE", stringVar(&c.Login.Mode)},
+		{"LOGIN_MAX_ATTEMPTS", intVar(&c.Login.MaxAttempts)},
+		{"LOGIN_LOCKOUT_BASE", durationVar(&c.Login.LockoutBase)},
+		{"LOGIN_LOCKOUT_MAX", durationVar(&c.Login.LockoutMax)},
+
+		{"COLLECTION_DAYS_BEFORE", intVar(&c.Collection.DaysBefore)},
+
+		{"LEAP_DAY_POLICY", func(value string) error { return c.LeapDayPolicy.UnmarshalText([]byte(value)) }},
+		{"SHUTDOWN_TIMEOUT", durationVar(&c.ShutdownTimeout)},
+	}
+}
+
+func stringVar(field *string) func(string) error {
+	return func(value string) error {
+		*field = value
+		return nil
+	}
+}
+
+func intVar(field *int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field = n
+		return nil
+	}
+}
+
+func int64Var(field *int64) func(string) error {
+	return func(value string) error {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*field = n
+		return nil
+	}
+}
+
+// durationVar читает длительность вида "720h".
+func durationVar(field *time.Duration) func(string) error {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field = d
+		return nil
+	}
+}
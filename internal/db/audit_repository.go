@@ -0,0 +1,63 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresAuditRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAuditRepository(db *sql.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+func (r *PostgresAuditRepository) RecordEvent(event *models.AuditEvent) error {
+	query := `INSERT INTO audit_events (event_type, actor_id, user_id, details) VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4)
+			RETURNING id, created_at`
+	err := r.db.QueryRow(query, event.Type, event.ActorID, event.UserID, event.Details).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось записать событие аудита: %v", err))
+	}
+	return nil
+}
+
+// GetEvents возвращает события, подходящие под filter, начиная с новых.
+func (r *PostgresAuditRepository) GetEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	query := `SELECT e.id, e.event_type, COALESCE(e.actor_id, 0), COALESCE(e.user_id, 0), e.details, e.created_at,
+				COALESCE(actor.username, ''), COALESCE(target.username, '')
+			FROM audit_events e
+			LEFT JOIN users actor ON actor.id = e.actor_id
+			LEFT JOIN users target ON target.id = e.user_id
+			WHERE ($1 = 0 OR e.actor_id = $1 OR e.user_id = $1)
+				AND ($2 = '' OR e.event_type = $2 OR left(e.event_type, length($2) + 1) = $2 || '.')
+			ORDER BY e.created_at DESC, e.id DESC
+			LIMIT $3`
+	rows, err := r.db.Query(query, filter.UserID, filter.Type, auditLimit(filter.Limit))
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить журнал аудита: %v", err))
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+	for rows.Next() {
+		var e models.AuditEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.ActorID, &e.UserID, &e.Details, &e.CreatedAt, &e.ActorName, &e.UserName); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении события аудита: %v", err))
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// auditLimit ограничивает размер выборки журнала.
+func auditLimit(limit int) int {
+	if limit <= 0 || limit > MaxAuditLimit {
+		return MaxAuditLimit
+	}
+	return limit
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresChannelRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresChannelRepository(db *sql.DB) *PostgresChannelRepository {
+	return &PostgresChannelRepository{db: db}
+}
+
+func (r *PostgresChannelRepository) GetChannel(key string) (*models.Channel, error) {
+	query := `SELECT key, channel_id, access_hash, bot_added, created_at FROM channels WHERE key = $1`
+
+	var channel models.Channel
+	err := r.db.QueryRow(query, key).Scan(&channel.Key, &channel.ChannelID, &channel.AccessHash, &channel.BotAdded, &channel.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "канал не найден")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить канал: %v", err))
+	}
+	return &channel, nil
+}
+
+// SaveChannel сохраняет канал, заменяя канал с тем же ключом вместе с его участниками.
+func (r *PostgresChannelRepository) SaveChannel(channel *models.Channel) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить канал: %v", err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM channels WHERE key = $1`, channel.Key); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить канал: %v", err))
+	}
+	query := `INSERT INTO channels (key, channel_id, access_hash, bot_added, created_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(query, channel.Key, channel.ChannelID, channel.AccessHash, channel.BotAdded, channel.CreatedAt); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить канал: %v", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить канал: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresChannelRepository) SetChannelBotAdded(key string) error {
+	result, err := r.db.Exec(`UPDATE channels SET bot_added = true WHERE key = $1`, key)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось обновить канал: %v", err))
+	}
+	return checkAffected(result, "канал не найден")
+}
+
+func (r *PostgresChannelRepository) GetChannelMembers(key string) ([]int64, error) {
+	rows, err := r.db.Query(`SELECT telegram_id FROM channel_members WHERE channel_key = $1 ORDER BY added_at`, key)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить участников канала: %v", err))
+	}
+	defer rows.Close()
+
+	var members []int64
+	for rows.Next() {
+		var telegramID int64
+		if err := rows.Scan(&telegramID); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении участника канала: %v", err))
+		}
+		members = append(members, telegramID)
+	}
+	return members, nil
+}
+
+func (r *PostgresChannelRepository) AddChannelMember(key string, telegramID int64) error {
+	query := `INSERT INTO channel_members (channel_key, telegram_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, key, telegramID); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось добавить участника канала: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresChannelRepository) RemoveChannelMember(key string, telegramID int64) error {
+	result, err := r.db.Exec(`DELETE FROM channel_members WHERE channel_key = $1 AND telegram_id = $2`, key, telegramID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить участника канала: %v", err))
+	}
+	return checkAffected(result, "участник канала не найден")
+}
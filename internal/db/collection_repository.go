@@ -0,0 +1,136 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresCollectionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresCollectionRepository(db *sql.DB) *PostgresCollectionRepository {
+	return &PostgresCollectionRepository{db: db}
+}
+
+const collectionColumns = `collections.id, collections.user_id, users.username, collections.birthday,
+			COALESCE(collections.organizer_id, 0), COALESCE(organizers.username, ''), collections.created_at
+			FROM collections
+			JOIN users ON collections.user_id = users.id
+			LEFT JOIN users organizers ON collections.organizer_id = organizers.id`
+
+// CreateCollection открывает сбор. Если сбор к этому дню рождения уже открыт,
+// возвращает false и ничего не меняет.
+func (r *PostgresCollectionRepository) CreateCollection(c *models.Collection) (bool, error) {
+	query := `INSERT INTO collections (user_id, birthday) VALUES ($1, $2)
+			ON CONFLICT (user_id, birthday) DO NOTHING
+			RETURNING id, created_at`
+	err := r.db.QueryRow(query, c.UserID, c.Birthday.Format("2006-01-02")).Scan(&c.ID, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.New(400, fmt.Sprintf("не удалось открыть сбор: %v", err))
+	}
+	return true, nil
+}
+
+func (r *PostgresCollectionRepository) GetCollection(id int64) (*models.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` WHERE collections.id = $1`
+
+	var c models.Collection
+	err := r.db.QueryRow(query, id).Scan(&c.ID, &c.UserID, &c.Username, &c.Birthday, &c.OrganizerID, &c.OrganizerName, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "сбор не найден")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить сбор: %v", err))
+	}
+	return &c, nil
+}
+
+// GetCollections возвращает сборы к дням рождения не раньше from, начиная с ближайших.
+func (r *PostgresCollectionRepository) GetCollections(from time.Time) ([]models.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` WHERE collections.birthday >= $1 ORDER BY collections.birthday, users.username`
+	rows, err := r.db.Query(query, from.Format("2006-01-02"))
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить сборы: %v", err))
+	}
+	defer rows.Close()
+
+	var collections []models.Collection
+	for rows.Next() {
+		var c models.Collection
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Username, &c.Birthday, &c.OrganizerID, &c.OrganizerName, &c.CreatedAt); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении сбора: %v", err))
+		}
+		collections = append(collections, c)
+	}
+	return collections, nil
+}
+
+// SetCollectionOrganizer назначает организатора сбора, если его ещё нет.
+func (r *PostgresCollectionRepository) SetCollectionOrganizer(id, userID int64) error {
+	result, err := r.db.Exec(`UPDATE collections SET organizer_id = $1 WHERE id = $2 AND organizer_id IS NULL`, userID, id)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось назначить организатора: %v", err))
+	}
+	return checkAffected(result, "у сбора уже есть организатор")
+}
+
+// SavePledge записывает или меняет обещанную сумму участника.
+func (r *PostgresCollectionRepository) SavePledge(p *models.CollectionPledge) error {
+	query := `INSERT INTO collection_pledges (collection_id, user_id, amount, updated_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (collection_id, user_id) DO UPDATE
+			SET amount = EXCLUDED.amount, updated_at = EXCLUDED.updated_at`
+	if _, err := r.db.Exec(query, p.CollectionID, p.UserID, p.Amount, p.UpdatedAt); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить взнос: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresCollectionRepository) DeletePledge(collectionID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM collection_pledges WHERE collection_id = $1 AND user_id = $2`, collectionID, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить взнос: %v", err))
+	}
+	return checkAffected(result, "взнос не найден")
+}
+
+func (r *PostgresCollectionRepository) MarkPledgePaid(collectionID, userID int64, at time.Time) error {
+	result, err := r.db.Exec(`UPDATE collection_pledges SET paid_at = $1, updated_at = $1 WHERE collection_id = $2 AND user_id = $3`, at, collectionID, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось отметить оплату: %v", err))
+	}
+	return checkAffected(result, "взнос не найден")
+}
+
+func (r *PostgresCollectionRepository) GetPledges(collectionID int64) ([]models.CollectionPledge, error) {
+	query := `SELECT collection_pledges.collection_id, collection_pledges.user_id, users.username, collection_pledges.amount,
+				collection_pledges.paid_at, collection_pledges.updated_at
+			FROM collection_pledges
+			JOIN users ON collection_pledges.user_id = users.id
+			WHERE collection_pledges.collection_id = $1
+			ORDER BY users.username`
+	rows, err := r.db.Query(query, collectionID)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить взносы: %v", err))
+	}
+	defer rows.Close()
+
+	var pledges []models.CollectionPledge
+	for rows.Next() {
+		var p models.CollectionPledge
+		var paidAt sql.NullTime
+		if err := rows.Scan(&p.CollectionID, &p.UserID, &p.Username, &p.Amount, &paidAt, &p.UpdatedAt); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении взноса: %v", err))
+		}
+		p.PaidAt = paidAt.Time
+		pledges = append(pledges, p)
+	}
+	return pledges, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresDialogRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDialogRepository(db *sql.DB) *PostgresDialogRepository {
+	return &PostgresDialogRepository{db: db}
+}
+
+func (r *PostgresDialogRepository) GetDialogState(chatID int64) (*models.DialogState, error) {
+	query := `SELECT chat_id, command, step, data, updated_at FROM dialog_states WHERE chat_id = $1`
+
+	var state models.DialogState
+	var data []byte
+	err := r.db.QueryRow(query, chatID).Scan(&state.ChatID, &state.Command, &state.Step, &data, &state.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "диалог не найден")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить состояние диалога: %v", err))
+	}
+
+	if err := json.Unmarshal(data, &state.Data); err != nil {
+		return nil, errors.New(500, fmt.Sprintf("некорректные данные диалога: %v", err))
+	}
+	return &state, nil
+}
+
+func (r *PostgresDialogRepository) SaveDialogState(state *models.DialogState) error {
+	data, err := json.Marshal(state.Data)
+	if err != nil {
+		return errors.New(500, fmt.Sprintf("не удалось сохранить состояние диалога: %v", err))
+	}
+
+	query := `INSERT INTO dialog_states (chat_id, command, step, data, updated_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (chat_id) DO UPDATE
+			SET command = EXCLUDED.command, step = EXCLUDED.step, data = EXCLUDED.data, updated_at = EXCLUDED.updated_at`
+	_, err = r.db.Exec(query, state.ChatID, state.Command, state.Step, data, state.UpdatedAt)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить состояние диалога: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresDialogRepository) DeleteDialogState(chatID int64) error {
+	_, err := r.db.Exec(`DELETE FROM dialog_states WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить состояние диалога: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresDialogRepository) DeleteStaleDialogStates(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM dialog_states WHERE updated_at < $1`, before)
+	if err != nil {
+		return 0, errors.New(400, fmt.Sprintf("не удалось удалить устаревшие диалоги: %v", err))
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.New(400, fmt.Sprintf("не удалось удалить устаревшие диалоги: %v", err))
+	}
+	return deleted, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresLoginAttemptRepository(db *sql.DB) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{db: db}
+}
+
+func (r *PostgresLoginAttemptRepository) GetLoginAttempt(scope, key string) (*models.LoginAttempt, error) {
+	query := `SELECT scope, key, failures, last_failure_at, locked_until FROM login_attempts WHERE scope = $1 AND key = $2`
+
+	var attempt models.LoginAttempt
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRow(query, scope, key).Scan(&attempt.Scope, &attempt.Key, &attempt.Failures, &attempt.LastFailureAt, &lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "попыток входа не найдено")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить попытки входа: %v", err))
+	}
+	attempt.LockedUntil = lockedUntil.Time
+	return &attempt, nil
+}
+
+// AddLoginFailure атомарно увеличивает счётчик неудачных попыток и возвращает
+// новое значение. Если предыдущая неудача была раньше since, счёт начинается заново.
+func (r *PostgresLoginAttemptRepository) AddLoginFailure(scope, key string, at, since time.Time) (int, error) {
+	query := `INSERT INTO login_attempts (scope, key, failures, last_failure_at) VALUES ($1, $2, 1, $3)
+			ON CONFLICT (scope, key) DO UPDATE
+			SET failures = CASE WHEN login_attempts.last_failure_at < $4 THEN 1 ELSE login_attempts.failures + 1 END,
+				last_failure_at = EXCLUDED.last_failure_at
+			RETURNING failures`
+
+	var failures int
+	if err := r.db.QueryRow(query, scope, key, at, since).Scan(&failures); err != nil {
+		return 0, errors.New(400, fmt.Sprintf("не удалось записать попытку входа: %v", err))
+	}
+	return failures, nil
+}
+
+func (r *PostgresLoginAttemptRepository) LockLogin(scope, key string, until time.Time) error {
+	result, err := r.db.Exec(`UPDATE login_attempts SET locked_until = $1 WHERE scope = $2 AND key = $3`, until, scope, key)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось заблокировать вход: %v", err))
+	}
+	return checkAffected(result, "попыток входа не найдено")
+}
+
+// DeleteLoginAttempts сбрасывает счётчик и блокировку и возвращает число удалённых записей.
+func (r *PostgresLoginAttemptRepository) DeleteLoginAttempts(scope, key string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM login_attempts WHERE scope = $1 AND key = $2`, scope, key)
+	if err != nil {
+		return 0, errors.New(400, fmt.Sprintf("не удалось сбросить попытки входа: %v", err))
+	}
+	return result.RowsAffected()
+}
+
+func (r *PostgresLoginAttemptRepository) DeleteStaleLoginAttempts(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM login_attempts WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < now())`, before)
+	if err != nil {
+		return 0, errors.New(400, fmt.Sprintf("не удалось удалить попытки входа: %v", err))
+	}
+	return result.RowsAffected()
+}
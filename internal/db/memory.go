@@ -0,0 +1,368 @@
+package db
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+// MemoryUserRepository хранит пользователей в памяти процесса.
+// Используется в тестах вместо PostgresUserRepository.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	users  map[int64]models.User
+	// deleteHooks удаляют данные пользователя из других репозиториев в памяти,
+	// как это делают ON DELETE CASCADE и ON DELETE SET NULL в Postgres.
+	deleteHooks []func(userID int64)
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users: make(map[int64]models.User),
+	}
+}
+
+func (r *MemoryUserRepository) CreateUser(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Username == user.Username {
+			return errors.New(409, "Пользователь с таким именем уже существует")
+		}
+		if u.TelegramID == user.TelegramID {
+			return errors.New(409, "На этот телеграмм аккаунт уже зарегистрирован пользователь")
+		}
+	}
+
+	r.nextID++
+	user.ID = r.nextID
+	if user.NotifyTime == "" {
+		user.NotifyTime = DefaultNotifyTime
+	}
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) GetAllUsers() ([]*models.UserBirthLayout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []*models.UserBirthLayout
+	for _, u := range r.sortedUsers() {
+		layout := birthLayout(u)
+		users = append(users, &layout)
+	}
+	return users, nil
+}
+
+func (r *MemoryUserRepository) GetUsersWithBirthday(dates []string) ([]models.UserBirthLayout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []models.UserBirthLayout
+	for _, u := range r.sortedUsers() {
+		for _, date := range dates {
+			if u.Birthday.Format("01-02") == date {
+				users = append(users, birthLayout(u))
+				break
+			}
+		}
+	}
+	return users, nil
+}
+
+func (r *MemoryUserRepository) GetUserByID(userID int64) (*models.User, error) {
+	u, ok := r.getByID(userID)
+	if !ok {
+		return nil, errors.New(404, "пользователь не найден")
+	}
+	return &u, nil
+}
+
+func (r *MemoryUserRepository) GetUserByName(username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Username == username {
+			return &u, nil
+		}
+	}
+	return nil, errors.New(404, "пользователь не найден")
+}
+
+func (r *MemoryUserRepository) GetUserByTgID(telegramID int64) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.TelegramID == telegramID {
+			return &u, nil
+		}
+	}
+	return nil, errors.New(404, "пользователь не найден")
+}
+
+func (r *MemoryUserRepository) SetUserBirthday(telegramID int64, birthday string) error {
+	date, err := time.Parse("2006-01-02", birthday)
+	if err != nil {
+		return errors.New(400, "ошибка в обновлении дня рождения: неверный формат даты")
+	}
+
+	return r.updateByTgID(telegramID, func(u *models.User) { u.Birthday = date })
+}
+
+func (r *MemoryUserRepository) SetUserTimezone(telegramID int64, timezone string) error {
+	return r.updateByTgID(telegramID, func(u *models.User) { u.Timezone = timezone })
+}
+
+func (r *MemoryUserRepository) SetUserNotifyTime(telegramID int64, notifyTime string) error {
+	return r.updateByTgID(telegramID, func(u *models.User) { u.NotifyTime = notifyTime })
+}
+
+func (r *MemoryUserRepository) SetUserRole(userID int64, role models.Role) error {
+	return r.updateByID(userID, func(u *models.User) { u.Role = role })
+}
+
+func (r *MemoryUserRepository) SetUserBanned(userID int64, banned bool) error {
+	return r.updateByID(userID, func(u *models.User) { u.Banned = banned })
+}
+
+func (r *MemoryUserRepository) SetUserPassword(userID int64, passwordHash string) error {
+	return r.updateByID(userID, func(u *models.User) { u.Password = passwordHash })
+}
+
+func (r *MemoryUserRepository) SetUserPIN(userID int64, pinHash string) error {
+	return r.updateByID(userID, func(u *models.User) { u.PIN = pinHash })
+}
+
+func (r *MemoryUserRepository) DeleteUser(userID int64) error {
+	r.mu.Lock()
+	if _, ok := r.users[userID]; !ok {
+		r.mu.Unlock()
+		return errors.New(404, "пользователь не найден")
+	}
+	delete(r.users, userID)
+	hooks := r.deleteHooks
+	r.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(userID)
+	}
+	return nil
+}
+
+// onDelete регистрирует hook, который вызывается после удаления пользователя.
+func (r *MemoryUserRepository) onDelete(hook func(userID int64)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleteHooks = append(r.deleteHooks, hook)
+}
+
+func (r *MemoryUserRepository) updateByID(userID int64, update func(u *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return errors.New(404, "пользователь не найден")
+	}
+	update(&u)
+	r.users[userID] = u
+	return nil
+}
+
+func (r *MemoryUserRepository) updateByTgID(telegramID int64, update func(u *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, u := range r.users {
+		if u.TelegramID == telegramID {
+			update(&u)
+			r.users[id] = u
+			return nil
+		}
+	}
+	return errors.New(404, "пользователь не найден")
+}
+
+func (r *MemoryUserRepository) UpdateUser(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return errors.New(404, "пользователь не найден")
+	}
+	for id, u := range r.users {
+		if id != user.ID && (u.Username == user.Username || u.TelegramID == user.TelegramID) {
+			return errors.New(400, "ошибка в обновлении пользователя: нарушена уникальность")
+		}
+	}
+
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) getByID(id int64) (models.User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	return u, ok
+}
+
+// sortedUsers возвращает пользователей в порядке создания. Вызывающий должен держать r.mu.
+func (r *MemoryUserRepository) sortedUsers() []models.User {
+	users := make([]models.User, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users
+}
+
+func birthLayout(u models.User) models.UserBirthLayout {
+	return models.UserBirthLayout{
+		ID:         u.ID,
+		Username:   u.Username,
+		TelegramID: u.TelegramID,
+		Birthday:   u.Birthday,
+		Timezone:   u.Timezone,
+		NotifyTime: u.NotifyTime,
+		Role:       u.Role,
+		Banned:     u.Banned,
+	}
+}
+
+// MemorySubscriptionRepository хранит подписки в памяти процесса.
+// Данные пользователей берутся из переданного MemoryUserRepository.
+type MemorySubscriptionRepository struct {
+	mu     sync.RWMutex
+	users  *MemoryUserRepository
+	nextID int64
+	subs   []models.Subscription
+}
+
+func NewMemorySubscriptionRepository(users *MemoryUserRepository) *MemorySubscriptionRepository {
+	r := &MemorySubscriptionRepository{
+		users: users,
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemorySubscriptionRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subs := r.subs[:0]
+	for _, s := range r.subs {
+		if s.SubscriberID != userID && s.SubscribedUserID != userID {
+			subs = append(subs, s)
+		}
+	}
+	r.subs = subs
+}
+
+func (r *MemorySubscriptionRepository) CreateSubscription(sub *models.Subscription) error {
+	if _, ok := r.users.getByID(sub.SubscriberID); !ok {
+		return errors.New(400, "не удалось подписаться: пользователь не найден")
+	}
+	if _, ok := r.users.getByID(sub.SubscribedUserID); !ok {
+		return errors.New(400, "не удалось подписаться: пользователь не найден")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.subs {
+		if s.SubscriberID == sub.SubscriberID && s.SubscribedUserID == sub.SubscribedUserID {
+			return errors.New(400, "не удалось подписаться: подписка уже существует")
+		}
+	}
+
+	r.nextID++
+	sub.ID = r.nextID
+	r.subs = append(r.subs, *sub)
+	return nil
+}
+
+func (r *MemorySubscriptionRepository) DeleteSubscription(subscriberID, subscribedUserID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, s := range r.subs {
+		if s.SubscriberID == subscriberID && s.SubscribedUserID == subscribedUserID {
+			r.subs = append(r.subs[:i], r.subs[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New(404, "подписка не найдена")
+}
+
+func (r *MemorySubscriptionRepository) IsSubscribed(subscriberID, subscribedUserID int64) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.subs {
+		if s.SubscriberID == subscriberID && s.SubscribedUserID == subscribedUserID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemorySubscriptionRepository) GetSubscribers(userID int64) ([]models.UserBirthLayout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscribers []models.UserBirthLayout
+	for _, s := range r.subs {
+		if s.SubscriberID != userID {
+			continue
+		}
+		if u, ok := r.users.getByID(s.SubscribedUserID); ok {
+			subscribers = append(subscribers, birthLayout(u))
+		}
+	}
+	return subscribers, nil
+}
+
+func (r *MemorySubscriptionRepository) GetAllSubscriptions() (map[int64][]models.UserBirthLayout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := make(map[int64][]models.UserBirthLayout)
+	for _, s := range r.subs {
+		if u, ok := r.users.getByID(s.SubscribedUserID); ok {
+			subscriptions[s.SubscriberID] = append(subscriptions[s.SubscriberID], birthLayout(u))
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *MemorySubscriptionRepository) GetSubscribersOf(userID int64) ([]models.UserBirthLayout, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscribers []models.UserBirthLayout
+	for _, s := range r.subs {
+		if s.SubscribedUserID != userID {
+			continue
+		}
+		if u, ok := r.users.getByID(s.SubscriberID); ok {
+			subscribers = append(subscribers, birthLayout(u))
+		}
+	}
+	return subscribers, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/models"
+)
+
+// MemoryAuditRepository хранит журнал аудита в памяти процесса. Имена
+// пользователей берутся из переданного MemoryUserRepository.
+type MemoryAuditRepository struct {
+	mu     sync.RWMutex
+	users  *MemoryUserRepository
+	nextID int64
+	events []models.AuditEvent
+}
+
+func NewMemoryAuditRepository(users *MemoryUserRepository) *MemoryAuditRepository {
+	r := &MemoryAuditRepository{
+		users: users,
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryAuditRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.events {
+		if r.events[i].ActorID == userID {
+			r.events[i].ActorID = 0
+		}
+		if r.events[i].UserID == userID {
+			r.events[i].UserID = 0
+		}
+	}
+}
+
+func (r *MemoryAuditRepository) RecordEvent(event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	event.ID = r.nextID
+	event.CreatedAt = time.Now()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *MemoryAuditRepository) GetEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit := auditLimit(filter.Limit)
+	var events []models.AuditEvent
+	for i := len(r.events) - 1; i >= 0 && len(events) < limit; i-- {
+		e := r.events[i]
+		if filter.UserID != 0 && e.ActorID != filter.UserID && e.UserID != filter.UserID {
+			continue
+		}
+		if filter.Type != "" && e.Type != filter.Type && !strings.HasPrefix(e.Type, filter.Type+".") {
+			continue
+		}
+		if u, ok := r.users.getByID(e.ActorID); ok {
+			e.ActorName = u.Username
+		}
+		if u, ok := r.users.getByID(e.UserID); ok {
+			e.UserName = u.Username
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
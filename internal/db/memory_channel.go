@@ -0,0 +1,91 @@
+package db
+
+import (
+	"sync"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type MemoryChannelRepository struct {
+	mu       sync.RWMutex
+	channels map[string]models.Channel
+	members  map[string][]int64
+}
+
+func NewMemoryChannelRepository() *MemoryChannelRepository {
+	return &MemoryChannelRepository{
+		channels: make(map[string]models.Channel),
+		members:  make(map[string][]int64),
+	}
+}
+
+func (r *MemoryChannelRepository) GetChannel(key string) (*models.Channel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	channel, ok := r.channels[key]
+	if !ok {
+		return nil, errors.New(404, "канал не найден")
+	}
+	return &channel, nil
+}
+
+func (r *MemoryChannelRepository) SaveChannel(channel *models.Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.channels[channel.Key] = *channel
+	delete(r.members, channel.Key)
+	return nil
+}
+
+func (r *MemoryChannelRepository) SetChannelBotAdded(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	channel, ok := r.channels[key]
+	if !ok {
+		return errors.New(404, "канал не найден")
+	}
+	channel.BotAdded = true
+	r.channels[key] = channel
+	return nil
+}
+
+func (r *MemoryChannelRepository) GetChannelMembers(key string) ([]int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]int64(nil), r.members[key]...), nil
+}
+
+func (r *MemoryChannelRepository) AddChannelMember(key string, telegramID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.channels[key]; !ok {
+		return errors.New(404, "канал не найден")
+	}
+	for _, id := range r.members[key] {
+		if id == telegramID {
+			return nil
+		}
+	}
+	r.members[key] = append(r.members[key], telegramID)
+	return nil
+}
+
+func (r *MemoryChannelRepository) RemoveChannelMember(key string, telegramID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	members := r.members[key]
+	for i, id := range members {
+		if id == telegramID {
+			r.members[key] = append(members[:i], members[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New(404, "участник канала не найден")
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+// MemoryCollectionRepository хранит сборы на подарки в памяти процесса.
+// Данные пользователей берутся из переданного MemoryUserRepository.
+type MemoryCollectionRepository struct {
+	mu          sync.RWMutex
+	users       *MemoryUserRepository
+	nextID      int64
+	collections map[int64]models.Collection
+	pledges     map[int64][]models.CollectionPledge
+}
+
+func NewMemoryCollectionRepository(users *MemoryUserRepository) *MemoryCollectionRepository {
+	r := &MemoryCollectionRepository{
+		users:       users,
+		collections: make(map[int64]models.Collection),
+		pledges:     make(map[int64][]models.CollectionPledge),
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryCollectionRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, c := range r.collections {
+		if c.UserID == userID {
+			delete(r.collections, id)
+			delete(r.pledges, id)
+			continue
+		}
+		if c.OrganizerID == userID {
+			c.OrganizerID = 0
+			r.collections[id] = c
+		}
+	}
+	for id, pledges := range r.pledges {
+		kept := pledges[:0]
+		for _, p := range pledges {
+			if p.UserID != userID {
+				kept = append(kept, p)
+			}
+		}
+		r.pledges[id] = kept
+	}
+}
+
+func (r *MemoryCollectionRepository) CreateCollection(c *models.Collection) (bool, error) {
+	if _, ok := r.users.getByID(c.UserID); !ok {
+		return false, errors.New(400, "не удалось открыть сбор: пользователь не найден")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.collections {
+		if existing.UserID == c.UserID && existing.Birthday.Equal(c.Birthday) {
+			return false, nil
+		}
+	}
+
+	r.nextID++
+	c.ID = r.nextID
+	c.CreatedAt = time.Now()
+	r.collections[c.ID] = *c
+	return true, nil
+}
+
+func (r *MemoryCollectionRepository) GetCollection(id int64) (*models.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collections[id]
+	if !ok {
+		return nil, errors.New(404, "сбор не найден")
+	}
+	r.fillNames(&c)
+	return &c, nil
+}
+
+func (r *MemoryCollectionRepository) GetCollections(from time.Time) ([]models.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var collections []models.Collection
+	for _, c := range r.collections {
+		if c.Birthday.Format("2006-01-02") < from.Format("2006-01-02") {
+			continue
+		}
+		r.fillNames(&c)
+		collections = append(collections, c)
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		if !collections[i].Birthday.Equal(collections[j].Birthday) {
+			return collections[i].Birthday.Before(collections[j].Birthday)
+		}
+		return collections[i].Username < collections[j].Username
+	})
+	return collections, nil
+}
+
+// fillNames заполняет имена именинника и организатора. Вызывающий должен держать r.mu.
+func (r *MemoryCollectionRepository) fillNames(c *models.Collection) {
+	if user, ok := r.users.getByID(c.UserID); ok {
+		c.Username = user.Username
+	}
+	if organizer, ok := r.users.getByID(c.OrganizerID); ok {
+		c.OrganizerName = organizer.Username
+	}
+}
+
+func (r *MemoryCollectionRepository) SetCollectionOrganizer(id, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.collections[id]
+	if !ok {
+		return errors.New(404, "сбор не найден")
+	}
+	if c.OrganizerID != 0 {
+		return errors.New(404, "у сбора уже есть организатор")
+	}
+	c.OrganizerID = userID
+	r.collections[id] = c
+	return nil
+}
+
+func (r *MemoryCollectionRepository) SavePledge(p *models.CollectionPledge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collections[p.CollectionID]; !ok {
+		return errors.New(400, "не удалось сохранить взнос: сбор не найден")
+	}
+	if i := r.pledgeIndex(p.CollectionID, p.UserID); i >= 0 {
+		r.pledges[p.CollectionID][i].Amount = p.Amount
+		r.pledges[p.CollectionID][i].UpdatedAt = p.UpdatedAt
+		return nil
+	}
+	r.pledges[p.CollectionID] = append(r.pledges[p.CollectionID], *p)
+	return nil
+}
+
+func (r *MemoryCollectionRepository) DeletePledge(collectionID, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.pledgeIndex(collectionID, userID)
+	if i < 0 {
+		return errors.New(404, "взнос не найден")
+	}
+	pledges := r.pledges[collectionID]
+	r.pledges[collectionID] = append(pledges[:i], pledges[i+1:]...)
+	return nil
+}
+
+func (r *MemoryCollectionRepository) MarkPledgePaid(collectionID, userID int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.pledgeIndex(collectionID, userID)
+	if i < 0 {
+		return errors.New(404, "взнос не найден")
+	}
+	r.pledges[collectionID][i].PaidAt = at
+	r.pledges[collectionID][i].UpdatedAt = at
+	return nil
+}
+
+func (r *MemoryCollectionRepository) GetPledges(collectionID int64) ([]models.CollectionPledge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pledges []models.CollectionPledge
+	for _, p := range r.pledges[collectionID] {
+		if user, ok := r.users.getByID(p.UserID); ok {
+			p.Username = user.Username
+		}
+		pledges = append(pledges, p)
+	}
+	sort.Slice(pledges, func(i, j int) bool { return pledges[i].Username < pledges[j].Username })
+	return pledges, nil
+}
+
+func (r *MemoryCollectionRepository) pledgeIndex(collectionID, userID int64) int {
+	for i, p := range r.pledges[collectionID] {
+		if p.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
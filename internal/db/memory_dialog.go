@@ -0,0 +1,72 @@
+package db
+
+import (
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type MemoryDialogRepository struct {
+	mu     sync.RWMutex
+	states map[int64]models.DialogState
+}
+
+func NewMemoryDialogRepository() *MemoryDialogRepository {
+	return &MemoryDialogRepository{
+		states: make(map[int64]models.DialogState),
+	}
+}
+
+func (r *MemoryDialogRepository) GetDialogState(chatID int64) (*models.DialogState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.states[chatID]
+	if !ok {
+		return nil, errors.New(404, "диалог не найден")
+	}
+	state.Data = copyData(state.Data)
+	return &state, nil
+}
+
+func (r *MemoryDialogRepository) SaveDialogState(state *models.DialogState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *state
+	saved.Data = copyData(state.Data)
+	r.states[state.ChatID] = saved
+	return nil
+}
+
+func (r *MemoryDialogRepository) DeleteDialogState(chatID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.states, chatID)
+	return nil
+}
+
+func (r *MemoryDialogRepository) DeleteStaleDialogStates(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for chatID, state := range r.states {
+		if state.UpdatedAt.Before(before) {
+			delete(r.states, chatID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func copyData(data map[string]string) map[string]string {
+	copied := make(map[string]string, len(data))
+	for k, v := range data {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type loginAttemptKey struct {
+	scope, key string
+}
+
+type MemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[loginAttemptKey]models.LoginAttempt
+}
+
+func NewMemoryLoginAttemptRepository() *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{
+		attempts: make(map[loginAttemptKey]models.LoginAttempt),
+	}
+}
+
+func (r *MemoryLoginAttemptRepository) GetLoginAttempt(scope, key string) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[loginAttemptKey{scope, key}]
+	if !ok {
+		return nil, errors.New(404, "попыток входа не найдено")
+	}
+	return &attempt, nil
+}
+
+func (r *MemoryLoginAttemptRepository) AddLoginFailure(scope, key string, at, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := loginAttemptKey{scope, key}
+	attempt, ok := r.attempts[k]
+	if !ok {
+		attempt = models.LoginAttempt{Scope: scope, Key: key}
+	}
+	if attempt.LastFailureAt.Before(since) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	r.attempts[k] = attempt
+	return attempt.Failures, nil
+}
+
+func (r *MemoryLoginAttemptRepository) LockLogin(scope, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := loginAttemptKey{scope, key}
+	attempt, ok := r.attempts[k]
+	if !ok {
+		return errors.New(404, "попыток входа не найдено")
+	}
+	attempt.LockedUntil = until
+	r.attempts[k] = attempt
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) DeleteLoginAttempts(scope, key string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := loginAttemptKey{scope, key}
+	if _, ok := r.attempts[k]; !ok {
+		return 0, nil
+	}
+	delete(r.attempts, k)
+	return 1, nil
+}
+
+func (r *MemoryLoginAttemptRepository) DeleteStaleLoginAttempts(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var deleted int64
+	for k, attempt := range r.attempts {
+		if attempt.LastFailureAt.Before(before) && attempt.LockedUntil.Before(now) {
+			delete(r.attempts, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/models"
+)
+
+type MemoryNotificationRepository struct {
+	mu            sync.RWMutex
+	nextID        int64
+	notifications []models.Notification
+}
+
+func NewMemoryNotificationRepository(users *MemoryUserRepository) *MemoryNotificationRepository {
+	r := &MemoryNotificationRepository{}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryNotificationRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notifications := r.notifications[:0]
+	for _, n := range r.notifications {
+		if n.UserID != userID {
+			notifications = append(notifications, n)
+		}
+	}
+	r.notifications = notifications
+}
+
+func (r *MemoryNotificationRepository) ClaimNotification(userID int64, kind string, localDate time.Time, message string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	date := localDate.Format("2006-01-02")
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.Kind == kind && n.LocalDate.Format("2006-01-02") == date {
+			return false, nil
+		}
+	}
+
+	r.nextID++
+	r.notifications = append(r.notifications, models.Notification{
+		ID:        r.nextID,
+		UserID:    userID,
+		Kind:      kind,
+		LocalDate: localDate,
+		Message:   message,
+		SentAt:    time.Now(),
+	})
+	return true, nil
+}
+
+func (r *MemoryNotificationRepository) ReleaseNotification(userID int64, kind string, localDate time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	date := localDate.Format("2006-01-02")
+	for i, n := range r.notifications {
+		if n.UserID == userID && n.Kind == kind && n.LocalDate.Format("2006-01-02") == date {
+			r.notifications = append(r.notifications[:i], r.notifications[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *MemoryNotificationRepository) GetLastNotified(kind string, since time.Time) (map[int64]time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	from := since.Format("2006-01-02")
+	last := make(map[int64]time.Time)
+	for _, n := range r.notifications {
+		date := n.LocalDate.Format("2006-01-02")
+		if n.Kind != kind || date < from {
+			continue
+		}
+		if prev, ok := last[n.UserID]; !ok || date > prev.Format("2006-01-02") {
+			last[n.UserID] = n.LocalDate
+		}
+	}
+	return last, nil
+}
+
+func (r *MemoryNotificationRepository) GetNotifications(userID int64) ([]models.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var notifications []models.Notification
+	for _, n := range r.notifications {
+		if n.UserID == userID {
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications, nil
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"sync"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type MemoryRecoveryCodeRepository struct {
+	mu    sync.Mutex
+	codes map[int64]models.RecoveryCode
+}
+
+func NewMemoryRecoveryCodeRepository(users *MemoryUserRepository) *MemoryRecoveryCodeRepository {
+	r := &MemoryRecoveryCodeRepository{
+		codes: make(map[int64]models.RecoveryCode),
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryRecoveryCodeRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.codes, userID)
+}
+
+func (r *MemoryRecoveryCodeRepository) SaveRecoveryCode(code *models.RecoveryCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codes[code.UserID] = *code
+	return nil
+}
+
+func (r *MemoryRecoveryCodeRepository) GetRecoveryCode(userID int64) (*models.RecoveryCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[userID]
+	if !ok {
+		return nil, errors.New(404, "код восстановления не найден")
+	}
+	return &code, nil
+}
+
+func (r *MemoryRecoveryCodeRepository) AddRecoveryAttempt(userID int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[userID]
+	if !ok {
+		return 0, errors.New(404, "код восстановления не найден")
+	}
+	code.Attempts++
+	r.codes[userID] = code
+	return code.Attempts, nil
+}
+
+func (r *MemoryRecoveryCodeRepository) DeleteRecoveryCode(userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.codes[userID]; !ok {
+		return errors.New(404, "код восстановления не найден")
+	}
+	delete(r.codes, userID)
+	return nil
+}
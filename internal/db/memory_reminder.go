@@ -0,0 +1,58 @@
+package db
+
+import (
+	"sort"
+	"sync"
+)
+
+type MemoryReminderRepository struct {
+	mu      sync.RWMutex
+	offsets map[int64][]int
+}
+
+func NewMemoryReminderRepository(users *MemoryUserRepository) *MemoryReminderRepository {
+	r := &MemoryReminderRepository{
+		offsets: make(map[int64][]int),
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryReminderRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.offsets, userID)
+}
+
+func (r *MemoryReminderRepository) GetReminderOffsets(userID int64) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]int(nil), r.offsets[userID]...), nil
+}
+
+func (r *MemoryReminderRepository) GetAllReminderOffsets() (map[int64][]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	offsets := make(map[int64][]int, len(r.offsets))
+	for userID, days := range r.offsets {
+		offsets[userID] = append([]int(nil), days...)
+	}
+	return offsets, nil
+}
+
+func (r *MemoryReminderRepository) SetReminderOffsets(userID int64, offsets []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(offsets) == 0 {
+		delete(r.offsets, userID)
+		return nil
+	}
+
+	days := append([]int(nil), offsets...)
+	sort.Sort(sort.Reverse(sort.IntSlice(days)))
+	r.offsets[userID] = days
+	return nil
+}
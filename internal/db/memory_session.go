@@ -0,0 +1,108 @@
+package db
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type MemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[int64]models.Session
+}
+
+func NewMemorySessionRepository(users *MemoryUserRepository) *MemorySessionRepository {
+	r := &MemorySessionRepository{
+		sessions: make(map[int64]models.Session),
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemorySessionRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for chatID, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, chatID)
+		}
+	}
+}
+
+func (r *MemorySessionRepository) CreateSession(session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ChatID] = *session
+	return nil
+}
+
+func (r *MemorySessionRepository) GetSession(chatID int64) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[chatID]
+	if !ok {
+		return nil, errors.New(404, "сессия не найдена")
+	}
+	return &session, nil
+}
+
+func (r *MemorySessionRepository) GetUserSessions(userID int64) ([]models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (r *MemorySessionRepository) TouchSession(chatID int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[chatID]
+	if !ok {
+		return errors.New(404, "сессия не найдена")
+	}
+	session.LastSeenAt = at
+	r.sessions[chatID] = session
+	return nil
+}
+
+func (r *MemorySessionRepository) DeleteSession(chatID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sessions[chatID]; !ok {
+		return errors.New(404, "сессия не найдена")
+	}
+	delete(r.sessions, chatID)
+	return nil
+}
+
+func (r *MemorySessionRepository) DeleteExpiredSessions(idleBefore, createdBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for chatID, session := range r.sessions {
+		if (!idleBefore.IsZero() && session.LastSeenAt.Before(idleBefore)) ||
+			(!createdBefore.IsZero() && session.CreatedAt.Before(createdBefore)) {
+			delete(r.sessions, chatID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
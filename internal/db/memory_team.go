@@ -0,0 +1,188 @@
+package db
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+// MemoryTeamRepository хранит команды в памяти процесса.
+// Данные участников берутся из переданного MemoryUserRepository.
+type MemoryTeamRepository struct {
+	mu      sync.RWMutex
+	users   *MemoryUserRepository
+	nextID  int64
+	teams   map[int64]models.Team
+	members map[int64][]models.TeamMember
+}
+
+func NewMemoryTeamRepository(users *MemoryUserRepository) *MemoryTeamRepository {
+	r := &MemoryTeamRepository{
+		users:   users,
+		teams:   make(map[int64]models.Team),
+		members: make(map[int64][]models.TeamMember),
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryTeamRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, t := range r.teams {
+		if t.CreatedBy == userID {
+			t.CreatedBy = 0
+			r.teams[id] = t
+		}
+	}
+	for id, members := range r.members {
+		kept := members[:0]
+		for _, m := range members {
+			if m.UserID != userID {
+				kept = append(kept, m)
+			}
+		}
+		r.members[id] = kept
+	}
+}
+
+func (r *MemoryTeamRepository) CreateTeam(team *models.Team) error {
+	if _, ok := r.users.getByID(team.CreatedBy); !ok {
+		return errors.New(400, "не удалось создать команду: пользователь не найден")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.teams {
+		if t.Name == team.Name {
+			return errors.New(409, "команда с таким названием уже существует")
+		}
+	}
+
+	r.nextID++
+	team.ID = r.nextID
+	team.CreatedAt = time.Now()
+	team.Members = 1
+	r.teams[team.ID] = *team
+	r.members[team.ID] = []models.TeamMember{{TeamID: team.ID, UserID: team.CreatedBy, IsAdmin: true, JoinedAt: team.CreatedAt}}
+	return nil
+}
+
+func (r *MemoryTeamRepository) GetTeamByName(name string) (*models.Team, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.teams {
+		if t.Name == name {
+			t.Members = len(r.members[t.ID])
+			return &t, nil
+		}
+	}
+	return nil, errors.New(404, "команда не найдена")
+}
+
+func (r *MemoryTeamRepository) GetTeams() ([]models.Team, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedTeams(func(models.Team) bool { return true }), nil
+}
+
+func (r *MemoryTeamRepository) GetUserTeams(userID int64) ([]models.Team, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedTeams(func(t models.Team) bool {
+		return r.memberIndex(t.ID, userID) >= 0
+	}), nil
+}
+
+func (r *MemoryTeamRepository) sortedTeams(keep func(models.Team) bool) []models.Team {
+	var teams []models.Team
+	for _, t := range r.teams {
+		if !keep(t) {
+			continue
+		}
+		t.Members = len(r.members[t.ID])
+		teams = append(teams, t)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams
+}
+
+func (r *MemoryTeamRepository) GetTeamMembers(teamID int64) ([]models.TeamMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var members []models.TeamMember
+	for _, m := range r.members[teamID] {
+		user, ok := r.users.getByID(m.UserID)
+		if !ok {
+			continue
+		}
+		m.Username = user.Username
+		m.TelegramID = user.TelegramID
+		m.Birthday = user.Birthday
+		m.Banned = user.Banned
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Username < members[j].Username })
+	return members, nil
+}
+
+func (r *MemoryTeamRepository) AddTeamMember(teamID, userID int64) error {
+	if _, ok := r.users.getByID(userID); !ok {
+		return errors.New(400, "не удалось добавить участника команды: пользователь не найден")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.teams[teamID]; !ok {
+		return errors.New(404, "команда не найдена")
+	}
+	if r.memberIndex(teamID, userID) >= 0 {
+		return errors.New(409, "пользователь уже состоит в команде")
+	}
+	r.members[teamID] = append(r.members[teamID], models.TeamMember{TeamID: teamID, UserID: userID, JoinedAt: time.Now()})
+	return nil
+}
+
+func (r *MemoryTeamRepository) RemoveTeamMember(teamID, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.memberIndex(teamID, userID)
+	if i < 0 {
+		return errors.New(404, "пользователь не состоит в команде")
+	}
+	members := r.members[teamID]
+	r.members[teamID] = append(members[:i], members[i+1:]...)
+	return nil
+}
+
+func (r *MemoryTeamRepository) SetTeamAdmin(teamID, userID int64, admin bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.memberIndex(teamID, userID)
+	if i < 0 {
+		return errors.New(404, "пользователь не состоит в команде")
+	}
+	r.members[teamID][i].IsAdmin = admin
+	return nil
+}
+
+func (r *MemoryTeamRepository) memberIndex(teamID, userID int64) int {
+	for i, m := range r.members[teamID] {
+		if m.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
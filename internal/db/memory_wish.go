@@ -0,0 +1,136 @@
+package db
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+// MemoryWishRepository хранит пожелания в памяти процесса.
+// Имена авторов берутся из переданного MemoryUserRepository.
+type MemoryWishRepository struct {
+	mu     sync.RWMutex
+	users  *MemoryUserRepository
+	wishes []models.Wish
+}
+
+func NewMemoryWishRepository(users *MemoryUserRepository) *MemoryWishRepository {
+	r := &MemoryWishRepository{
+		users: users,
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryWishRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wishes := r.wishes[:0]
+	for _, w := range r.wishes {
+		if w.UserID != userID && w.AuthorID != userID {
+			wishes = append(wishes, w)
+		}
+	}
+	r.wishes = wishes
+}
+
+func (r *MemoryWishRepository) SaveWish(wish *models.Wish) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i := r.index(wish.UserID, wish.AuthorID, wish.Birthday); i >= 0 {
+		r.wishes[i] = *wish
+		return nil
+	}
+	r.wishes = append(r.wishes, *wish)
+	return nil
+}
+
+func (r *MemoryWishRepository) DeleteWish(userID, authorID int64, birthday time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(userID, authorID, birthday)
+	if i < 0 {
+		return errors.New(404, "пожелание не найдено")
+	}
+	r.wishes = append(r.wishes[:i], r.wishes[i+1:]...)
+	return nil
+}
+
+func (r *MemoryWishRepository) DeleteWishes(userID int64, birthday time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wishes := r.wishes[:0]
+	for _, w := range r.wishes {
+		if w.UserID != userID || !sameDate(w.Birthday, birthday) {
+			wishes = append(wishes, w)
+		}
+	}
+	r.wishes = wishes
+	return nil
+}
+
+func (r *MemoryWishRepository) GetWishes(userID int64, birthday time.Time) ([]models.Wish, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var wishes []models.Wish
+	for _, w := range r.wishes {
+		if w.UserID != userID || !sameDate(w.Birthday, birthday) {
+			continue
+		}
+		if author, ok := r.users.getByID(w.AuthorID); ok {
+			w.AuthorName = author.Username
+		}
+		wishes = append(wishes, w)
+	}
+	sort.SliceStable(wishes, func(i, j int) bool { return wishes[i].CreatedAt.Before(wishes[j].CreatedAt) })
+	return wishes, nil
+}
+
+func (r *MemoryWishRepository) GetPendingWishes(until time.Time) ([]models.Wish, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	last := until.Format("2006-01-02")
+	var wishes []models.Wish
+	for _, w := range r.wishes {
+		if w.Birthday.Format("2006-01-02") > last {
+			continue
+		}
+		if author, ok := r.users.getByID(w.AuthorID); ok {
+			w.AuthorName = author.Username
+		}
+		wishes = append(wishes, w)
+	}
+	sort.SliceStable(wishes, func(i, j int) bool {
+		a, b := wishes[i], wishes[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		if !sameDate(a.Birthday, b.Birthday) {
+			return a.Birthday.Before(b.Birthday)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return wishes, nil
+}
+
+func (r *MemoryWishRepository) index(userID, authorID int64, birthday time.Time) int {
+	for i, w := range r.wishes {
+		if w.UserID == userID && w.AuthorID == authorID && sameDate(w.Birthday, birthday) {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
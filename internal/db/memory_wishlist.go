@@ -0,0 +1,150 @@
+package db
+
+import (
+	"sync"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+// MemoryWishlistRepository хранит списки желаний в памяти процесса.
+// Имена бронирующих берутся из переданного MemoryUserRepository.
+type MemoryWishlistRepository struct {
+	mu     sync.RWMutex
+	users  *MemoryUserRepository
+	nextID int64
+	items  []models.WishlistItem
+}
+
+func NewMemoryWishlistRepository(users *MemoryUserRepository) *MemoryWishlistRepository {
+	r := &MemoryWishlistRepository{
+		users: users,
+	}
+	users.onDelete(r.deleteUser)
+	return r
+}
+
+func (r *MemoryWishlistRepository) deleteUser(userID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := r.items[:0]
+	for _, item := range r.items {
+		if item.UserID == userID {
+			continue
+		}
+		if item.ReservedBy == userID {
+			item.ReservedBy = 0
+			item.ReservedAt = time.Time{}
+		}
+		items = append(items, item)
+	}
+	r.items = items
+}
+
+func (r *MemoryWishlistRepository) AddWishlistItem(item *models.WishlistItem) error {
+	if _, ok := r.users.getByID(item.UserID); !ok {
+		return errors.New(400, "не удалось добавить подарок: пользователь не найден")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	item.ID = r.nextID
+	item.CreatedAt = time.Now()
+	r.items = append(r.items, *item)
+	return nil
+}
+
+func (r *MemoryWishlistRepository) GetWishlistItem(id int64) (*models.WishlistItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.index(id)
+	if i < 0 {
+		return nil, errors.New(404, "подарок не найден")
+	}
+	item := r.withName(r.items[i])
+	return &item, nil
+}
+
+func (r *MemoryWishlistRepository) GetWishlist(userID int64) ([]models.WishlistItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var items []models.WishlistItem
+	for _, item := range r.items {
+		if item.UserID == userID {
+			items = append(items, r.withName(item))
+		}
+	}
+	return items, nil
+}
+
+func (r *MemoryWishlistRepository) GetWishlistOwners() (map[int64]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owners := make(map[int64]bool)
+	for _, item := range r.items {
+		owners[item.UserID] = true
+	}
+	return owners, nil
+}
+
+func (r *MemoryWishlistRepository) DeleteWishlistItem(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(id)
+	if i < 0 {
+		return errors.New(404, "подарок не найден")
+	}
+	r.items = append(r.items[:i], r.items[i+1:]...)
+	return nil
+}
+
+func (r *MemoryWishlistRepository) ReserveWishlistItem(id, userID int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(id)
+	if i < 0 || r.items[i].ReservedBy != 0 {
+		return errors.New(404, "подарок уже забронирован")
+	}
+	r.items[i].ReservedBy = userID
+	r.items[i].ReservedAt = at
+	return nil
+}
+
+func (r *MemoryWishlistRepository) ReleaseWishlistItem(id, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(id)
+	if i < 0 || r.items[i].ReservedBy != userID {
+		return errors.New(404, "вы не бронировали этот подарок")
+	}
+	r.items[i].ReservedBy = 0
+	r.items[i].ReservedAt = time.Time{}
+	return nil
+}
+
+// withName заполняет имя забронировавшего. Вызывающий должен держать r.mu.
+func (r *MemoryWishlistRepository) withName(item models.WishlistItem) models.WishlistItem {
+	if user, ok := r.users.getByID(item.ReservedBy); ok {
+		item.ReservedName = user.Username
+	}
+	return item
+}
+
+func (r *MemoryWishlistRepository) index(id int64) int {
+	for i, item := range r.items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
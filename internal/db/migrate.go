@@ -0,0 +1,225 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID - ключ advisory lock, под которым применяются миграции,
+// чтобы несколько экземпляров приложения не мигрировали базу одновременно.
+const migrationLockID = 20240608
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, errors.New(500, fmt.Sprintf("не удалось прочитать миграции: %v", err))
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		parts := migrationFileName.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, errors.New(500, fmt.Sprintf("некорректное имя файла миграции: %s", entry.Name()))
+		}
+
+		version, _ := strconv.Atoi(parts[1])
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, errors.New(500, fmt.Sprintf("не удалось прочитать миграцию %s: %v", entry.Name(), err))
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		}
+		if m.Name != parts[2] {
+			return nil, errors.New(500, fmt.Sprintf("у миграции %d разные имена: %s и %s", version, m.Name, parts[2]))
+		}
+
+		if parts[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, errors.New(500, fmt.Sprintf("у миграции %d должны быть up и down файлы", m.Version))
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrateUp применяет все ещё не применённые миграции и возвращает их количество.
+func MigrateUp(conn *sql.DB) (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ensureMigrationsTable(conn); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		done, err := applyMigration(conn, m, true)
+		if err != nil {
+			return applied, err
+		}
+		if done {
+			logging.Logger.Printf("Применена миграция %04d_%s", m.Version, m.Name)
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// MigrateDown откатывает steps последних применённых миграций и возвращает их количество.
+func MigrateDown(conn *sql.DB, steps int) (int, error) {
+	statuses, err := GetMigrationsStatus(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	rolledBack := 0
+	for i := len(statuses) - 1; i >= 0 && rolledBack < steps; i-- {
+		if !statuses[i].Applied {
+			continue
+		}
+
+		done, err := applyMigration(conn, statuses[i].Migration, false)
+		if err != nil {
+			return rolledBack, err
+		}
+		if done {
+			logging.Logger.Printf("Откачена миграция %04d_%s", statuses[i].Version, statuses[i].Name)
+			rolledBack++
+		}
+	}
+
+	return rolledBack, nil
+}
+
+func GetMigrationsStatus(conn *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureMigrationsTable(conn); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.New(500, fmt.Sprintf("не удалось получить применённые миграции: %v", err))
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, errors.New(500, fmt.Sprintf("ошибка в получении миграции: %v", err))
+		}
+		appliedAt[version] = at
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		at, ok := appliedAt[m.Version]
+		statuses = append(statuses, MigrationStatus{Migration: m, Applied: ok, AppliedAt: at})
+	}
+
+	return statuses, nil
+}
+
+func ensureMigrationsTable(conn *sql.DB) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INT PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT now())`
+	if _, err := conn.Exec(query); err != nil {
+		return errors.New(500, fmt.Sprintf("не удалось создать таблицу schema_migrations: %v", err))
+	}
+	return nil
+}
+
+// applyMigration выполняет одну миграцию в отдельной транзакции. Наличие записи
+// в schema_migrations проверяется уже под блокировкой, поэтому false означает,
+// что миграцию успел применить (или откатить) другой экземпляр.
+func applyMigration(conn *sql.DB, m Migration, up bool) (bool, error) {
+	tx, err := conn.Begin()
+	if err != nil {
+		return false, errors.New(500, fmt.Sprintf("не удалось начать транзакцию: %v", err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return false, errors.New(500, fmt.Sprintf("не удалось заблокировать миграции: %v", err))
+	}
+
+	var applied bool
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)`, m.Version).Scan(&applied)
+	if err != nil {
+		return false, errors.New(500, fmt.Sprintf("ошибка в проверке миграции %d: %v", m.Version, err))
+	}
+	if applied == up {
+		return false, nil
+	}
+
+	script, record := m.Up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+	if !up {
+		script, record = m.Down, `DELETE FROM schema_migrations WHERE version = $1 AND name = $2`
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		return false, errors.New(500, fmt.Sprintf("ошибка в миграции %04d_%s: %v", m.Version, m.Name, err))
+	}
+	if _, err := tx.Exec(record, m.Version, m.Name); err != nil {
+		return false, errors.New(500, fmt.Sprintf("не удалось записать миграцию %d: %v", m.Version, err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, errors.New(500, fmt.Sprintf("не удалось применить миграцию %d: %v", m.Version, err))
+	}
+	return true, nil
+}
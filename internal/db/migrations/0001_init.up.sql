@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL UNIQUE,
+    password VARCHAR(255) NOT NULL,
+    telegram_id BIGINT NOT NULL UNIQUE,
+    birthday DATE
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    subscribed_user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (user_id, subscribed_user_id)
+);
@@ -0,0 +1,5 @@
+CREATE TABLE reminder_offsets (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    days_before INT NOT NULL CHECK (days_before BETWEEN 0 AND 365),
+    PRIMARY KEY (user_id, days_before)
+);
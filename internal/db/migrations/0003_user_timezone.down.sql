@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS notification_log;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS notify_time,
+    DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,7 @@
+CREATE TABLE dialog_states (
+    chat_id BIGINT PRIMARY KEY,
+    command VARCHAR(64) NOT NULL,
+    step INT NOT NULL DEFAULT 0,
+    data JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS admin_actions;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS banned,
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS audit_events_user_id_idx;
+DROP INDEX IF EXISTS audit_events_actor_id_idx;
+
+DELETE FROM audit_events WHERE event_type NOT LIKE 'admin.%';
+UPDATE audit_events SET event_type = substr(event_type, length('admin.') + 1);
+
+ALTER TABLE audit_events ALTER COLUMN event_type TYPE VARCHAR(32);
+ALTER TABLE audit_events RENAME CONSTRAINT audit_events_user_id_fkey TO admin_actions_target_id_fkey;
+ALTER TABLE audit_events RENAME CONSTRAINT audit_events_actor_id_fkey TO admin_actions_admin_id_fkey;
+ALTER TABLE audit_events RENAME COLUMN user_id TO target_id;
+ALTER TABLE audit_events RENAME COLUMN actor_id TO admin_id;
+ALTER TABLE audit_events RENAME COLUMN event_type TO action;
+
+ALTER INDEX audit_events_created_at_idx RENAME TO admin_actions_created_at_idx;
+ALTER INDEX audit_events_pkey RENAME TO admin_actions_pkey;
+ALTER SEQUENCE audit_events_id_seq RENAME TO admin_actions_id_seq;
+ALTER TABLE audit_events RENAME TO admin_actions;
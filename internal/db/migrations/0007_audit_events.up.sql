@@ -0,0 +1,16 @@
+ALTER TABLE admin_actions RENAME TO audit_events;
+ALTER SEQUENCE admin_actions_id_seq RENAME TO audit_events_id_seq;
+ALTER INDEX admin_actions_pkey RENAME TO audit_events_pkey;
+ALTER INDEX admin_actions_created_at_idx RENAME TO audit_events_created_at_idx;
+
+ALTER TABLE audit_events RENAME COLUMN action TO event_type;
+ALTER TABLE audit_events RENAME COLUMN admin_id TO actor_id;
+ALTER TABLE audit_events RENAME COLUMN target_id TO user_id;
+ALTER TABLE audit_events RENAME CONSTRAINT admin_actions_admin_id_fkey TO audit_events_actor_id_fkey;
+ALTER TABLE audit_events RENAME CONSTRAINT admin_actions_target_id_fkey TO audit_events_user_id_fkey;
+ALTER TABLE audit_events ALTER COLUMN event_type TYPE VARCHAR(64);
+
+UPDATE audit_events SET event_type = 'admin.' || event_type;
+
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id);
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN pin VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+CREATE TABLE channels (
+    key VARCHAR(64) PRIMARY KEY,
+    channel_id BIGINT NOT NULL,
+    access_hash BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE channel_members (
+    channel_key VARCHAR(64) NOT NULL REFERENCES channels(key) ON DELETE CASCADE,
+    telegram_id BIGINT NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (channel_key, telegram_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS collection_pledges;
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,8 @@
+CREATE TABLE wishes (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    author_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    birthday DATE NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, author_id, birthday)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE wishlist_items (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    reserved_by INT REFERENCES users(id) ON DELETE SET NULL,
+    reserved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX wishlist_items_user_id_idx ON wishlist_items (user_id);
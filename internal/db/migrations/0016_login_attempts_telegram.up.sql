@@ -0,0 +1 @@
+DELETE FROM login_attempts WHERE scope <> 'telegram';
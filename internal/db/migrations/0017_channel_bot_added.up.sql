@@ -0,0 +1,3 @@
+ALTER TABLE channels ADD COLUMN bot_added BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE channels SET bot_added = true;
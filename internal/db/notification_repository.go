@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresNotificationRepository(db *sql.DB) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{db: db}
+}
+
+func (r *PostgresNotificationRepository) ClaimNotification(userID int64, kind string, localDate time.Time, message string) (bool, error) {
+	query := `INSERT INTO notification_log (user_id, kind, local_date, message) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, kind, local_date) DO NOTHING`
+	result, err := r.db.Exec(query, userID, kind, localDate.Format("2006-01-02"), message)
+	if err != nil {
+		return false, errors.New(400, fmt.Sprintf("не удалось записать уведомление: %v", err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.New(400, fmt.Sprintf("не удалось записать уведомление: %v", err))
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *PostgresNotificationRepository) ReleaseNotification(userID int64, kind string, localDate time.Time) error {
+	query := `DELETE FROM notification_log WHERE user_id = $1 AND kind = $2 AND local_date = $3`
+	if _, err := r.db.Exec(query, userID, kind, localDate.Format("2006-01-02")); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить уведомление: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresNotificationRepository) GetLastNotified(kind string, since time.Time) (map[int64]time.Time, error) {
+	query := `SELECT user_id, MAX(local_date) FROM notification_log WHERE kind = $1 AND local_date >= $2 GROUP BY user_id`
+	rows, err := r.db.Query(query, kind, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить уведомления: %v", err))
+	}
+	defer rows.Close()
+
+	last := make(map[int64]time.Time)
+	for rows.Next() {
+		var userID int64
+		var date time.Time
+		if err := rows.Scan(&userID, &date); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении уведомления: %v", err))
+		}
+		last[userID] = date
+	}
+	return last, nil
+}
+
+// GetNotifications возвращает журнал уведомлений пользователя от старых к новым.
+func (r *PostgresNotificationRepository) GetNotifications(userID int64) ([]models.Notification, error) {
+	query := `SELECT id, user_id, kind, local_date, message, sent_at FROM notification_log WHERE user_id = $1 ORDER BY sent_at, id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить уведомления: %v", err))
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.LocalDate, &n.Message, &n.SentAt); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении уведомления: %v", err))
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
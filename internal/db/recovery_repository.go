@@ -0,0 +1,64 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresRecoveryCodeRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRecoveryCodeRepository(db *sql.DB) *PostgresRecoveryCodeRepository {
+	return &PostgresRecoveryCodeRepository{db: db}
+}
+
+// SaveRecoveryCode сохраняет код восстановления, заменяя предыдущий код пользователя.
+func (r *PostgresRecoveryCodeRepository) SaveRecoveryCode(code *models.RecoveryCode) error {
+	query := `INSERT INTO recovery_codes (user_id, code_hash, attempts, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id) DO UPDATE
+			SET code_hash = EXCLUDED.code_hash, attempts = EXCLUDED.attempts, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at`
+	_, err := r.db.Exec(query, code.UserID, code.CodeHash, code.Attempts, code.CreatedAt, code.ExpiresAt)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить код восстановления: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresRecoveryCodeRepository) GetRecoveryCode(userID int64) (*models.RecoveryCode, error) {
+	query := `SELECT user_id, code_hash, attempts, created_at, expires_at FROM recovery_codes WHERE user_id = $1`
+
+	var code models.RecoveryCode
+	err := r.db.QueryRow(query, userID).Scan(&code.UserID, &code.CodeHash, &code.Attempts, &code.CreatedAt, &code.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "код восстановления не найден")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить код восстановления: %v", err))
+	}
+	return &code, nil
+}
+
+// AddRecoveryAttempt атомарно увеличивает счётчик попыток ввода кода и возвращает его значение.
+func (r *PostgresRecoveryCodeRepository) AddRecoveryAttempt(userID int64) (int, error) {
+	var attempts int
+	err := r.db.QueryRow(`UPDATE recovery_codes SET attempts = attempts + 1 WHERE user_id = $1 RETURNING attempts`, userID).Scan(&attempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors.New(404, "код восстановления не найден")
+		}
+		return 0, errors.New(400, fmt.Sprintf("не удалось обновить код восстановления: %v", err))
+	}
+	return attempts, nil
+}
+
+func (r *PostgresRecoveryCodeRepository) DeleteRecoveryCode(userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить код восстановления: %v", err))
+	}
+	return checkAffected(result, "код восстановления не найден")
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BirthdayGreetings/internal/errors"
+)
+
+type PostgresReminderRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresReminderRepository(db *sql.DB) *PostgresReminderRepository {
+	return &PostgresReminderRepository{db: db}
+}
+
+func (r *PostgresReminderRepository) GetReminderOffsets(userID int64) ([]int, error) {
+	query := `SELECT days_before FROM reminder_offsets WHERE user_id = $1 ORDER BY days_before DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить напоминания: %v", err))
+	}
+	defer rows.Close()
+
+	var offsets []int
+	for rows.Next() {
+		var days int
+		if err := rows.Scan(&days); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении напоминания: %v", err))
+		}
+		offsets = append(offsets, days)
+	}
+	return offsets, nil
+}
+
+func (r *PostgresReminderRepository) GetAllReminderOffsets() (map[int64][]int, error) {
+	rows, err := r.db.Query(`SELECT user_id, days_before FROM reminder_offsets ORDER BY user_id, days_before DESC`)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить напоминания: %v", err))
+	}
+	defer rows.Close()
+
+	offsets := make(map[int64][]int)
+	for rows.Next() {
+		var userID int64
+		var days int
+		if err := rows.Scan(&userID, &days); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении напоминания: %v", err))
+		}
+		offsets[userID] = append(offsets[userID], days)
+	}
+	return offsets, nil
+}
+
+func (r *PostgresReminderRepository) SetReminderOffsets(userID int64, offsets []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.New(500, fmt.Sprintf("не удалось начать транзакцию: %v", err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM reminder_offsets WHERE user_id = $1`, userID); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось обновить напоминания: %v", err))
+	}
+
+	for _, days := range offsets {
+		_, err := tx.Exec(`INSERT INTO reminder_offsets (user_id, days_before) VALUES ($1, $2)`, userID, days)
+		if err != nil {
+			return errors.New(400, fmt.Sprintf("не удалось обновить напоминания: %v", err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(500, fmt.Sprintf("не удалось обновить напоминания: %v", err))
+	}
+	return nil
+}
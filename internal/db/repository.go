@@ -0,0 +1,189 @@
+package db
+
+import (
+	"time"
+
+	"BirthdayGreetings/internal/models"
+)
+
+// DefaultNotifyTime - время уведомлений по умолчанию, совпадает с DEFAULT колонки users.notify_time.
+const DefaultNotifyTime = "09:00"
+
+type UserRepository interface {
+	CreateUser(user *models.User) error
+	GetAllUsers() ([]*models.UserBirthLayout, error)
+	GetUsersWithBirthday(dates []string) ([]models.UserBirthLayout, error)
+	GetUserByID(userID int64) (*models.User, error)
+	GetUserByName(username string) (*models.User, error)
+	GetUserByTgID(telegramID int64) (*models.User, error)
+	SetUserBirthday(telegramID int64, birthday string) error
+	SetUserTimezone(telegramID int64, timezone string) error
+	SetUserNotifyTime(telegramID int64, notifyTime string) error
+	SetUserRole(userID int64, role models.Role) error
+	SetUserBanned(userID int64, banned bool) error
+	SetUserPassword(userID int64, passwordHash string) error
+	SetUserPIN(userID int64, pinHash string) error
+	UpdateUser(user *models.User) error
+	DeleteUser(userID int64) error
+}
+
+type SubscriptionRepository interface {
+	CreateSubscription(sub *models.Subscription) error
+	DeleteSubscription(subscriberID, subscribedUserID int64) error
+	IsSubscribed(subscriberID, subscribedUserID int64) (bool, error)
+	GetSubscribers(userID int64) ([]models.UserBirthLayout, error)
+	GetSubscribersOf(userID int64) ([]models.UserBirthLayout, error)
+	// GetAllSubscriptions возвращает подписки всех пользователей по ID подписчика.
+	GetAllSubscriptions() (map[int64][]models.UserBirthLayout, error)
+}
+
+type ReminderRepository interface {
+	GetReminderOffsets(userID int64) ([]int, error)
+	GetAllReminderOffsets() (map[int64][]int, error)
+	SetReminderOffsets(userID int64, offsets []int) error
+}
+
+type NotificationRepository interface {
+	// ClaimNotification записывает уведомление в журнал и возвращает false,
+	// если уведомление этого вида за localDate пользователю уже отправлялось.
+	ClaimNotification(userID int64, kind string, localDate time.Time, message string) (bool, error)
+	// ReleaseNotification удаляет запись, сделанную ClaimNotification, например
+	// если уведомление не удалось отправить и его нужно повторить.
+	ReleaseNotification(userID int64, kind string, localDate time.Time) error
+	// GetLastNotified возвращает по ID пользователя последнюю дату уведомления
+	// вида kind не раньше since.
+	GetLastNotified(kind string, since time.Time) (map[int64]time.Time, error)
+	GetNotifications(userID int64) ([]models.Notification, error)
+}
+
+type SessionRepository interface {
+	CreateSession(session *models.Session) error
+	GetSession(chatID int64) (*models.Session, error)
+	GetUserSessions(userID int64) ([]models.Session, error)
+	TouchSession(chatID int64, at time.Time) error
+	DeleteSession(chatID int64) error
+	DeleteExpiredSessions(idleBefore, createdBefore time.Time) (int64, error)
+}
+
+type DialogRepository interface {
+	GetDialogState(chatID int64) (*models.DialogState, error)
+	SaveDialogState(state *models.DialogState) error
+	DeleteDialogState(chatID int64) error
+	DeleteStaleDialogStates(before time.Time) (int64, error)
+}
+
+// MaxAuditLimit ограничивает количество событий аудита в одной выборке.
+const MaxAuditLimit = 100
+
+type AuditRepository interface {
+	RecordEvent(event *models.AuditEvent) error
+	GetEvents(filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type LoginAttemptRepository interface {
+	GetLoginAttempt(scope, key string) (*models.LoginAttempt, error)
+	// AddLoginFailure увеличивает счётчик неудачных попыток и возвращает его значение.
+	// Неудачи раньше since забываются, и счёт начинается заново.
+	AddLoginFailure(scope, key string, at, since time.Time) (int, error)
+	LockLogin(scope, key string, until time.Time) error
+	DeleteLoginAttempts(scope, key string) (int64, error)
+	// DeleteStaleLoginAttempts удаляет счётчики, последняя неудача в которых
+	// раньше before, и возвращает число удалённых записей.
+	DeleteStaleLoginAttempts(before time.Time) (int64, error)
+}
+
+type RecoveryCodeRepository interface {
+	SaveRecoveryCode(code *models.RecoveryCode) error
+	GetRecoveryCode(userID int64) (*models.RecoveryCode, error)
+	AddRecoveryAttempt(userID int64) (int, error)
+	DeleteRecoveryCode(userID int64) error
+}
+
+type ChannelRepository interface {
+	GetChannel(key string) (*models.Channel, error)
+	SaveChannel(channel *models.Channel) error
+	// SetChannelBotAdded отмечает, что бот назначен администратором канала.
+	SetChannelBotAdded(key string) error
+	// GetChannelMembers возвращает Telegram ID пользователей, которых бот добавил в канал.
+	GetChannelMembers(key string) ([]int64, error)
+	AddChannelMember(key string, telegramID int64) error
+	RemoveChannelMember(key string, telegramID int64) error
+}
+
+type TeamRepository interface {
+	// CreateTeam создаёт команду, её создатель становится администратором команды.
+	CreateTeam(team *models.Team) error
+	GetTeamByName(name string) (*models.Team, error)
+	GetTeams() ([]models.Team, error)
+	GetUserTeams(userID int64) ([]models.Team, error)
+	GetTeamMembers(teamID int64) ([]models.TeamMember, error)
+	AddTeamMember(teamID, userID int64) error
+	RemoveTeamMember(teamID, userID int64) error
+	SetTeamAdmin(teamID, userID int64, admin bool) error
+}
+
+type CollectionRepository interface {
+	// CreateCollection открывает сбор и возвращает false, если сбор к этой
+	// дате празднования уже открыт.
+	CreateCollection(c *models.Collection) (bool, error)
+	GetCollection(id int64) (*models.Collection, error)
+	GetCollections(from time.Time) ([]models.Collection, error)
+	SetCollectionOrganizer(id, userID int64) error
+	SavePledge(p *models.CollectionPledge) error
+	DeletePledge(collectionID, userID int64) error
+	MarkPledgePaid(collectionID, userID int64, at time.Time) error
+	GetPledges(collectionID int64) ([]models.CollectionPledge, error)
+}
+
+type WishRepository interface {
+	SaveWish(wish *models.Wish) error
+	DeleteWish(userID, authorID int64, birthday time.Time) error
+	GetWishes(userID int64, birthday time.Time) ([]models.Wish, error)
+	// GetPendingWishes возвращает пожелания ко всем дням рождения до until
+	// включительно, сгруппированные по имениннику и дате.
+	GetPendingWishes(until time.Time) ([]models.Wish, error)
+	// DeleteWishes удаляет все пожелания ко дню рождения userID в дату birthday.
+	DeleteWishes(userID int64, birthday time.Time) error
+}
+
+type WishlistRepository interface {
+	AddWishlistItem(item *models.WishlistItem) error
+	GetWishlistItem(id int64) (*models.WishlistItem, error)
+	GetWishlist(userID int64) ([]models.WishlistItem, error)
+	// GetWishlistOwners возвращает ID пользователей, в чьих списках желаний есть подарки.
+	GetWishlistOwners() (map[int64]bool, error)
+	DeleteWishlistItem(id int64) error
+	ReserveWishlistItem(id, userID int64, at time.Time) error
+	ReleaseWishlistItem(id, userID int64) error
+}
+
+var (
+	_ UserRepository         = (*PostgresUserRepository)(nil)
+	_ UserRepository         = (*MemoryUserRepository)(nil)
+	_ SubscriptionRepository = (*PostgresSubscriptionRepository)(nil)
+	_ SubscriptionRepository = (*MemorySubscriptionRepository)(nil)
+	_ ReminderRepository     = (*PostgresReminderRepository)(nil)
+	_ ReminderRepository     = (*MemoryReminderRepository)(nil)
+	_ NotificationRepository = (*PostgresNotificationRepository)(nil)
+	_ NotificationRepository = (*MemoryNotificationRepository)(nil)
+	_ SessionRepository      = (*PostgresSessionRepository)(nil)
+	_ SessionRepository      = (*MemorySessionRepository)(nil)
+	_ DialogRepository       = (*PostgresDialogRepository)(nil)
+	_ DialogRepository       = (*MemoryDialogRepository)(nil)
+	_ AuditRepository        = (*PostgresAuditRepository)(nil)
+	_ AuditRepository        = (*MemoryAuditRepository)(nil)
+	_ LoginAttemptRepository = (*PostgresLoginAttemptRepository)(nil)
+	_ LoginAttemptRepository = (*MemoryLoginAttemptRepository)(nil)
+	_ RecoveryCodeRepository = (*PostgresRecoveryCodeRepository)(nil)
+	_ RecoveryCodeRepository = (*MemoryRecoveryCodeRepository)(nil)
+	_ ChannelRepository      = (*PostgresChannelRepository)(nil)
+	_ ChannelRepository      = (*MemoryChannelRepository)(nil)
+	_ TeamRepository         = (*PostgresTeamRepository)(nil)
+	_ TeamRepository         = (*MemoryTeamRepository)(nil)
+	_ CollectionRepository   = (*PostgresCollectionRepository)(nil)
+	_ CollectionRepository   = (*MemoryCollectionRepository)(nil)
+	_ WishRepository         = (*PostgresWishRepository)(nil)
+	_ WishRepository         = (*MemoryWishRepository)(nil)
+	_ WishlistRepository     = (*PostgresWishlistRepository)(nil)
+	_ WishlistRepository     = (*MemoryWishlistRepository)(nil)
+)
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+// CreateSession создаёт сессию для чата, заменяя предыдущую сессию этого чата.
+func (r *PostgresSessionRepository) CreateSession(session *models.Session) error {
+	query := `INSERT INTO sessions (chat_id, user_id, created_at, last_seen_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (chat_id) DO UPDATE
+			SET user_id = EXCLUDED.user_id, created_at = EXCLUDED.created_at, last_seen_at = EXCLUDED.last_seen_at`
+	_, err := r.db.Exec(query, session.ChatID, session.UserID, session.CreatedAt, session.LastSeenAt)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось создать сессию: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresSessionRepository) GetSession(chatID int64) (*models.Session, error) {
+	query := `SELECT chat_id, user_id, created_at, last_seen_at FROM sessions WHERE chat_id = $1`
+
+	var session models.Session
+	err := r.db.QueryRow(query, chatID).Scan(&session.ChatID, &session.UserID, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "сессия не найдена")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить сессию: %v", err))
+	}
+	return &session, nil
+}
+
+func (r *PostgresSessionRepository) GetUserSessions(userID int64) ([]models.Session, error) {
+	query := `SELECT chat_id, user_id, created_at, last_seen_at FROM sessions WHERE user_id = $1 ORDER BY created_at`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить сессии: %v", err))
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ChatID, &session.UserID, &session.CreatedAt, &session.LastSeenAt); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении сессии: %v", err))
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (r *PostgresSessionRepository) TouchSession(chatID int64, at time.Time) error {
+	result, err := r.db.Exec(`UPDATE sessions SET last_seen_at = $1 WHERE chat_id = $2`, at, chatID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось обновить сессию: %v", err))
+	}
+	return checkAffected(result, "сессия не найдена")
+}
+
+func (r *PostgresSessionRepository) DeleteSession(chatID int64) error {
+	result, err := r.db.Exec(`DELETE FROM sessions WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить сессию: %v", err))
+	}
+	return checkAffected(result, "сессия не найдена")
+}
+
+// DeleteExpiredSessions удаляет сессии, неактивные с idleBefore или созданные раньше createdBefore.
+// Нулевое время отключает соответствующую проверку.
+func (r *PostgresSessionRepository) DeleteExpiredSessions(idleBefore, createdBefore time.Time) (int64, error) {
+	query := `DELETE FROM sessions
+			WHERE ($1::timestamptz IS NOT NULL AND last_seen_at < $1)
+			OR ($2::timestamptz IS NOT NULL AND created_at < $2)`
+	result, err := r.db.Exec(query, nullTime(idleBefore), nullTime(createdBefore))
+	if err != nil {
+		return 0, errors.New(400, fmt.Sprintf("не удалось удалить просроченные сессии: %v", err))
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.New(400, fmt.Sprintf("не удалось удалить просроченные сессии: %v", err))
+	}
+	return deleted, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresSubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSubscriptionRepository(db *sql.DB) *PostgresSubscriptionRepository {
+	return &PostgresSubscriptionRepository{db: db}
+}
+
+func (r *PostgresSubscriptionRepository) CreateSubscription(sub *models.Subscription) error {
+	query := `INSERT INTO subscriptions (user_id, subscribed_user_id) VALUES ($1, $2) RETURNING id`
+	err := r.db.QueryRow(query, sub.SubscriberID, sub.SubscribedUserID).Scan(&sub.ID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось подписаться: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresSubscriptionRepository) DeleteSubscription(subscriberID, subscribedUserID int64) error {
+	query := `DELETE FROM subscriptions WHERE user_id = $1 AND subscribed_user_id = $2`
+	result, err := r.db.Exec(query, subscriberID, subscribedUserID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось отписаться: %v", err))
+	}
+
+	return checkAffected(result, "подписка не найдена")
+}
+
+func (r *PostgresSubscriptionRepository) IsSubscribed(subscriberID, subscribedUserID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM subscriptions WHERE user_id = $1 AND subscribed_user_id = $2)`
+	var exists bool
+	err := r.db.QueryRow(query, subscriberID, subscribedUserID).Scan(&exists)
+	if err != nil {
+		return false, errors.New(400, fmt.Sprintf("ошибка в проверке подписки: %v", err))
+	}
+	return exists, nil
+}
+
+// GetSubscribers возвращает пользователей, на которых подписан userID.
+func (r *PostgresSubscriptionRepository) GetSubscribers(userID int64) ([]models.UserBirthLayout, error) {
+	query := `SELECT users.id, users.username, users.telegram_id, users.birthday, users.timezone, users.notify_time, users.role, users.banned
+			FROM subscriptions
+			JOIN users ON subscriptions.subscribed_user_id = users.id
+			WHERE subscriptions.user_id = $1`
+	return r.queryUsers(query, userID)
+}
+
+// GetSubscribersOf возвращает пользователей, подписанных на userID.
+func (r *PostgresSubscriptionRepository) GetSubscribersOf(userID int64) ([]models.UserBirthLayout, error) {
+	query := `SELECT users.id, users.username, users.telegram_id, users.birthday, users.timezone, users.notify_time, users.role, users.banned
+			FROM subscriptions
+			JOIN users ON subscriptions.user_id = users.id
+			WHERE subscriptions.subscribed_user_id = $1`
+	return r.queryUsers(query, userID)
+}
+
+func (r *PostgresSubscriptionRepository) GetAllSubscriptions() (map[int64][]models.UserBirthLayout, error) {
+	query := `SELECT subscriptions.user_id, users.id, users.username, users.telegram_id, users.birthday, users.timezone, users.notify_time, users.role, users.banned
+			FROM subscriptions
+			JOIN users ON subscriptions.subscribed_user_id = users.id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить подписки: %v", err))
+	}
+	defer rows.Close()
+
+	subscriptions := make(map[int64][]models.UserBirthLayout)
+	for rows.Next() {
+		var subscriberID int64
+		var user models.UserBirthLayout
+		if err := rows.Scan(&subscriberID, &user.ID, &user.Username, &user.TelegramID, &user.Birthday, &user.Timezone, &user.NotifyTime, &user.Role, &user.Banned); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении подписки: %v", err))
+		}
+		subscriptions[subscriberID] = append(subscriptions[subscriberID], user)
+	}
+	return subscriptions, nil
+}
+
+func (r *PostgresSubscriptionRepository) queryUsers(query string, userID int64) ([]models.UserBirthLayout, error) {
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить пользователей: %v", err))
+	}
+	defer rows.Close()
+
+	var users []models.UserBirthLayout
+	for rows.Next() {
+		var user models.UserBirthLayout
+		if err := rows.Scan(&user.ID, &user.Username, &user.TelegramID, &user.Birthday, &user.Timezone, &user.NotifyTime, &user.Role, &user.Banned); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении пользователя: %v", err))
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
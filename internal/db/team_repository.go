@@ -0,0 +1,152 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type PostgresTeamRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTeamRepository(db *sql.DB) *PostgresTeamRepository {
+	return &PostgresTeamRepository{db: db}
+}
+
+// CreateTeam создаёт команду и делает её создателя администратором команды.
+func (r *PostgresTeamRepository) CreateTeam(team *models.Team) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось создать команду: %v", err))
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO teams (name, created_by) VALUES ($1, $2) RETURNING id, created_at`
+	if err := tx.QueryRow(query, team.Name, team.CreatedBy).Scan(&team.ID, &team.CreatedAt); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.New(409, "команда с таким названием уже существует")
+		}
+		return errors.New(400, fmt.Sprintf("не удалось создать команду: %v", err))
+	}
+	if _, err := tx.Exec(`INSERT INTO team_members (team_id, user_id, is_admin) VALUES ($1, $2, TRUE)`, team.ID, team.CreatedBy); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось создать команду: %v", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось создать команду: %v", err))
+	}
+	team.Members = 1
+	return nil
+}
+
+func (r *PostgresTeamRepository) GetTeamByName(name string) (*models.Team, error) {
+	query := `SELECT teams.id, teams.name, COALESCE(teams.created_by, 0), teams.created_at, COUNT(team_members.user_id)
+			FROM teams
+			LEFT JOIN team_members ON team_members.team_id = teams.id
+			WHERE teams.name = $1
+			GROUP BY teams.id`
+
+	var team models.Team
+	err := r.db.QueryRow(query, name).Scan(&team.ID, &team.Name, &team.CreatedBy, &team.CreatedAt, &team.Members)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "команда не найдена")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить команду: %v", err))
+	}
+	return &team, nil
+}
+
+// GetTeams возвращает все команды с количеством участников, упорядоченные по названию.
+func (r *PostgresTeamRepository) GetTeams() ([]models.Team, error) {
+	query := `SELECT teams.id, teams.name, COALESCE(teams.created_by, 0), teams.created_at, COUNT(team_members.user_id)
+			FROM teams
+			LEFT JOIN team_members ON team_members.team_id = teams.id
+			GROUP BY teams.id
+			ORDER BY teams.name`
+	return r.queryTeams(query)
+}
+
+// GetUserTeams возвращает команды, в которых состоит userID.
+func (r *PostgresTeamRepository) GetUserTeams(userID int64) ([]models.Team, error) {
+	query := `SELECT teams.id, teams.name, COALESCE(teams.created_by, 0), teams.created_at,
+				(SELECT COUNT(*) FROM team_members m WHERE m.team_id = teams.id)
+			FROM teams
+			JOIN team_members ON team_members.team_id = teams.id
+			WHERE team_members.user_id = $1
+			ORDER BY teams.name`
+	return r.queryTeams(query, userID)
+}
+
+func (r *PostgresTeamRepository) queryTeams(query string, args ...interface{}) ([]models.Team, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить команды: %v", err))
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	for rows.Next() {
+		var team models.Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.CreatedBy, &team.CreatedAt, &team.Members); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении команды: %v", err))
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+func (r *PostgresTeamRepository) GetTeamMembers(teamID int64) ([]models.TeamMember, error) {
+	query := `SELECT team_members.team_id, users.id, users.username, users.telegram_id, users.birthday, users.banned, team_members.is_admin, team_members.joined_at
+			FROM team_members
+			JOIN users ON team_members.user_id = users.id
+			WHERE team_members.team_id = $1
+			ORDER BY users.username`
+	rows, err := r.db.Query(query, teamID)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить участников команды: %v", err))
+	}
+	defer rows.Close()
+
+	var members []models.TeamMember
+	for rows.Next() {
+		var m models.TeamMember
+		if err := rows.Scan(&m.TeamID, &m.UserID, &m.Username, &m.TelegramID, &m.Birthday, &m.Banned, &m.IsAdmin, &m.JoinedAt); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении участника команды: %v", err))
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func (r *PostgresTeamRepository) AddTeamMember(teamID, userID int64) error {
+	_, err := r.db.Exec(`INSERT INTO team_members (team_id, user_id) VALUES ($1, $2)`, teamID, userID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.New(409, "пользователь уже состоит в команде")
+		}
+		return errors.New(400, fmt.Sprintf("не удалось добавить участника команды: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresTeamRepository) RemoveTeamMember(teamID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось исключить участника команды: %v", err))
+	}
+	return checkAffected(result, "пользователь не состоит в команде")
+}
+
+func (r *PostgresTeamRepository) SetTeamAdmin(teamID, userID int64, admin bool) error {
+	result, err := r.db.Exec(`UPDATE team_members SET is_admin = $1 WHERE team_id = $2 AND user_id = $3`, admin, teamID, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось изменить администратора команды: %v", err))
+	}
+	return checkAffected(result, "пользователь не состоит в команде")
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type PostgresUserRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+func (r *PostgresUserRepository) CreateUser(user *models.User) error {
+	query := `SELECT username, telegram_id FROM users WHERE username = $1 OR telegram_id = $2`
+	var tempUser models.User
+	err := r.db.QueryRow(query, user.Username, user.TelegramID).Scan(&tempUser.Username, &tempUser.TelegramID)
+	if err != nil && err != sql.ErrNoRows {
+		return errors.New(400, fmt.Sprintf("Ошибка: %v", err))
+	}
+
+	if err == nil {
+		if tempUser.Username == user.Username {
+			return errors.New(409, "Пользователь с таким именем уже существует")
+		}
+		return errors.New(409, "На этот телеграмм аккаунт уже зарегистрирован пользователь")
+	}
+
+	query = `INSERT INTO users (username, password, telegram_id, birthday) VALUES ($1, $2, $3, $4) RETURNING id, timezone, notify_time, role, banned`
+	err = r.db.QueryRow(query, user.Username, user.Password, user.TelegramID, user.Birthday).Scan(&user.ID, &user.Timezone, &user.NotifyTime, &user.Role, &user.Banned)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в создании пользователя: %v", err))
+	}
+	return nil
+}
+
+// GetUsersWithBirthday возвращает пользователей, чья дата рождения в формате MM-DD входит в dates.
+func (r *PostgresUserRepository) GetUsersWithBirthday(dates []string) ([]models.UserBirthLayout, error) {
+	query := `SELECT id, username, telegram_id, birthday, timezone, notify_time, role, banned FROM users WHERE to_char(birthday, 'MM-DD') = ANY($1)`
+	rows, err := r.db.Query(query, pq.Array(dates))
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("ошибка в получении пользователей: %v", err))
+	}
+	defer rows.Close()
+
+	var users []models.UserBirthLayout
+	for rows.Next() {
+		var user models.UserBirthLayout
+		if err := rows.Scan(&user.ID, &user.Username, &user.TelegramID, &user.Birthday, &user.Timezone, &user.NotifyTime, &user.Role, &user.Banned); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении пользователя: %v", err))
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) GetUserByID(userID int64) (*models.User, error) {
+	query := `SELECT id, username, password, pin, telegram_id, birthday, timezone, notify_time, role, banned FROM users WHERE id = $1`
+	return r.getUser(query, userID)
+}
+
+func (r *PostgresUserRepository) GetUserByName(username string) (*models.User, error) {
+	query := `SELECT id, username, password, pin, telegram_id, birthday, timezone, notify_time, role, banned FROM users WHERE username = $1`
+	return r.getUser(query, username)
+}
+
+func (r *PostgresUserRepository) GetUserByTgID(telegramID int64) (*models.User, error) {
+	query := `SELECT id, username, password, pin, telegram_id, birthday, timezone, notify_time, role, banned FROM users WHERE telegram_id = $1`
+	return r.getUser(query, telegramID)
+}
+
+func (r *PostgresUserRepository) getUser(query string, arg interface{}) (*models.User, error) {
+	row := r.db.QueryRow(query, arg)
+
+	var user models.User
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.PIN, &user.TelegramID, &user.Birthday, &user.Timezone, &user.NotifyTime, &user.Role, &user.Banned)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "пользователь не найден")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить пользователя: %v", err))
+	}
+
+	return &user, nil
+}
+
+func (r *PostgresUserRepository) GetAllUsers() ([]*models.UserBirthLayout, error) {
+	rows, err := r.db.Query(`SELECT id, username, birthday, telegram_id, timezone, notify_time, role, banned FROM users ORDER BY id`)
+	if err != nil {
+		return nil, errors.New(500, fmt.Sprintf("ошибка в получении пользователей: %v", err))
+	}
+	defer rows.Close()
+
+	var users []*models.UserBirthLayout
+	for rows.Next() {
+		var user models.UserBirthLayout
+		err := rows.Scan(&user.ID, &user.Username, &user.Birthday, &user.TelegramID, &user.Timezone, &user.NotifyTime, &user.Role, &user.Banned)
+		if err != nil {
+			return nil, errors.New(500, fmt.Sprintf("ошибка в получении пользователя: %v", err))
+		}
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+func (r *PostgresUserRepository) SetUserBirthday(telegramID int64, birthday string) error {
+	query := `UPDATE users SET birthday = $1 WHERE telegram_id = $2`
+	result, err := r.db.Exec(query, birthday, telegramID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении дня рождения: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+func (r *PostgresUserRepository) SetUserTimezone(telegramID int64, timezone string) error {
+	query := `UPDATE users SET timezone = $1 WHERE telegram_id = $2`
+	result, err := r.db.Exec(query, timezone, telegramID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении часового пояса: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+func (r *PostgresUserRepository) SetUserNotifyTime(telegramID int64, notifyTime string) error {
+	query := `UPDATE users SET notify_time = $1 WHERE telegram_id = $2`
+	result, err := r.db.Exec(query, notifyTime, telegramID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении времени уведомлений: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+func (r *PostgresUserRepository) SetUserRole(userID int64, role models.Role) error {
+	query := `UPDATE users SET role = $1 WHERE id = $2`
+	result, err := r.db.Exec(query, role, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении роли: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+func (r *PostgresUserRepository) SetUserBanned(userID int64, banned bool) error {
+	query := `UPDATE users SET banned = $1 WHERE id = $2`
+	result, err := r.db.Exec(query, banned, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении блокировки: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+func (r *PostgresUserRepository) SetUserPassword(userID int64, passwordHash string) error {
+	query := `UPDATE users SET password = $1 WHERE id = $2`
+	result, err := r.db.Exec(query, passwordHash, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении пароля: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+func (r *PostgresUserRepository) SetUserPIN(userID int64, pinHash string) error {
+	query := `UPDATE users SET pin = $1 WHERE id = $2`
+	result, err := r.db.Exec(query, pinHash, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении PIN-кода: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+func (r *PostgresUserRepository) UpdateUser(user *models.User) error {
+	query := `UPDATE users SET username = $1, password = $2, pin = $3, telegram_id = $4, birthday = $5, timezone = $6, notify_time = $7, role = $8, banned = $9 WHERE id = $10`
+	result, err := r.db.Exec(query, user.Username, user.Password, user.PIN, user.TelegramID, user.Birthday, user.Timezone, user.NotifyTime, user.Role, user.Banned, user.ID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в обновлении пользователя: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+// DeleteUser удаляет пользователя. Его подписки, подписки на него, сессии,
+// напоминания и журнал уведомлений удаляются каскадно, в журнале аудита
+// ссылки на пользователя обнуляются.
+func (r *PostgresUserRepository) DeleteUser(userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("ошибка в удалении пользователя: %v", err))
+	}
+	return checkAffected(result, "пользователь не найден")
+}
+
+// checkAffected возвращает ошибку 404 с текстом notFound, если запрос не изменил ни одной строки.
+func checkAffected(result sql.Result, notFound string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.New(404, fmt.Sprintf("нет строк для изменения: %v", err))
+	}
+
+	if rowsAffected == 0 {
+		return errors.New(404, notFound)
+	}
+
+	return nil
+}
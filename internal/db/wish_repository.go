@@ -0,0 +1,84 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresWishRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresWishRepository(db *sql.DB) *PostgresWishRepository {
+	return &PostgresWishRepository{db: db}
+}
+
+// SaveWish записывает пожелание. Повторное пожелание того же автора к тому же
+// дню рождения заменяет предыдущее.
+func (r *PostgresWishRepository) SaveWish(wish *models.Wish) error {
+	query := `INSERT INTO wishes (user_id, author_id, birthday, text, created_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, author_id, birthday) DO UPDATE
+			SET text = EXCLUDED.text, created_at = EXCLUDED.created_at`
+	_, err := r.db.Exec(query, wish.UserID, wish.AuthorID, wish.Birthday.Format("2006-01-02"), wish.Text, wish.CreatedAt)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось сохранить пожелание: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresWishRepository) DeleteWish(userID, authorID int64, birthday time.Time) error {
+	result, err := r.db.Exec(`DELETE FROM wishes WHERE user_id = $1 AND author_id = $2 AND birthday = $3`, userID, authorID, birthday.Format("2006-01-02"))
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить пожелание: %v", err))
+	}
+	return checkAffected(result, "пожелание не найдено")
+}
+
+func (r *PostgresWishRepository) DeleteWishes(userID int64, birthday time.Time) error {
+	_, err := r.db.Exec(`DELETE FROM wishes WHERE user_id = $1 AND birthday = $2`, userID, birthday.Format("2006-01-02"))
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить пожелания: %v", err))
+	}
+	return nil
+}
+
+// GetWishes возвращает пожелания ко дню рождения userID в дату birthday в порядке написания.
+func (r *PostgresWishRepository) GetWishes(userID int64, birthday time.Time) ([]models.Wish, error) {
+	query := `SELECT wishes.user_id, wishes.author_id, users.username, wishes.birthday, wishes.text, wishes.created_at
+			FROM wishes
+			JOIN users ON wishes.author_id = users.id
+			WHERE wishes.user_id = $1 AND wishes.birthday = $2
+			ORDER BY wishes.created_at`
+	return r.queryWishes(query, userID, birthday.Format("2006-01-02"))
+}
+
+func (r *PostgresWishRepository) GetPendingWishes(until time.Time) ([]models.Wish, error) {
+	query := `SELECT wishes.user_id, wishes.author_id, users.username, wishes.birthday, wishes.text, wishes.created_at
+			FROM wishes
+			JOIN users ON wishes.author_id = users.id
+			WHERE wishes.birthday <= $1
+			ORDER BY wishes.user_id, wishes.birthday, wishes.created_at`
+	return r.queryWishes(query, until.Format("2006-01-02"))
+}
+
+func (r *PostgresWishRepository) queryWishes(query string, args ...interface{}) ([]models.Wish, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить пожелания: %v", err))
+	}
+	defer rows.Close()
+
+	var wishes []models.Wish
+	for rows.Next() {
+		var w models.Wish
+		if err := rows.Scan(&w.UserID, &w.AuthorID, &w.AuthorName, &w.Birthday, &w.Text, &w.CreatedAt); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении пожелания: %v", err))
+		}
+		wishes = append(wishes, w)
+	}
+	return wishes, nil
+}
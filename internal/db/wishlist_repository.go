@@ -0,0 +1,122 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+)
+
+type PostgresWishlistRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresWishlistRepository(db *sql.DB) *PostgresWishlistRepository {
+	return &PostgresWishlistRepository{db: db}
+}
+
+const wishlistColumns = `wishlist_items.id, wishlist_items.user_id, wishlist_items.title,
+			COALESCE(wishlist_items.reserved_by, 0), COALESCE(users.username, ''), wishlist_items.reserved_at, wishlist_items.created_at
+			FROM wishlist_items
+			LEFT JOIN users ON wishlist_items.reserved_by = users.id`
+
+func (r *PostgresWishlistRepository) AddWishlistItem(item *models.WishlistItem) error {
+	query := `INSERT INTO wishlist_items (user_id, title) VALUES ($1, $2) RETURNING id, created_at`
+	if err := r.db.QueryRow(query, item.UserID, item.Title).Scan(&item.ID, &item.CreatedAt); err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось добавить подарок: %v", err))
+	}
+	return nil
+}
+
+func (r *PostgresWishlistRepository) GetWishlistItem(id int64) (*models.WishlistItem, error) {
+	query := `SELECT ` + wishlistColumns + ` WHERE wishlist_items.id = $1`
+	item, err := scanWishlistItem(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(404, "подарок не найден")
+		}
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить подарок: %v", err))
+	}
+	return item, nil
+}
+
+// GetWishlist возвращает список желаний пользователя в порядке добавления.
+func (r *PostgresWishlistRepository) GetWishlist(userID int64) ([]models.WishlistItem, error) {
+	query := `SELECT ` + wishlistColumns + ` WHERE wishlist_items.user_id = $1 ORDER BY wishlist_items.created_at, wishlist_items.id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить список желаний: %v", err))
+	}
+	defer rows.Close()
+
+	var items []models.WishlistItem
+	for rows.Next() {
+		item, err := scanWishlistItem(rows)
+		if err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении подарка: %v", err))
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+func (r *PostgresWishlistRepository) GetWishlistOwners() (map[int64]bool, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT user_id FROM wishlist_items`)
+	if err != nil {
+		return nil, errors.New(400, fmt.Sprintf("не удалось получить владельцев списков желаний: %v", err))
+	}
+	defer rows.Close()
+
+	owners := make(map[int64]bool)
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, errors.New(400, fmt.Sprintf("ошибка в получении владельца списка желаний: %v", err))
+		}
+		owners[userID] = true
+	}
+	return owners, nil
+}
+
+// rowScanner - общее у *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWishlistItem(row rowScanner) (*models.WishlistItem, error) {
+	var item models.WishlistItem
+	var reservedAt sql.NullTime
+	if err := row.Scan(&item.ID, &item.UserID, &item.Title, &item.ReservedBy, &item.ReservedName, &reservedAt, &item.CreatedAt); err != nil {
+		return nil, err
+	}
+	item.ReservedAt = reservedAt.Time
+	return &item, nil
+}
+
+func (r *PostgresWishlistRepository) DeleteWishlistItem(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM wishlist_items WHERE id = $1`, id)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось удалить подарок: %v", err))
+	}
+	return checkAffected(result, "подарок не найден")
+}
+
+// ReserveWishlistItem бронирует свободный подарок за userID.
+func (r *PostgresWishlistRepository) ReserveWishlistItem(id, userID int64, at time.Time) error {
+	result, err := r.db.Exec(`UPDATE wishlist_items SET reserved_by = $1, reserved_at = $2 WHERE id = $3 AND reserved_by IS NULL`, userID, at, id)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось забронировать подарок: %v", err))
+	}
+	return checkAffected(result, "подарок уже забронирован")
+}
+
+// ReleaseWishlistItem снимает бронь, если подарок забронировал userID.
+func (r *PostgresWishlistRepository) ReleaseWishlistItem(id, userID int64) error {
+	result, err := r.db.Exec(`UPDATE wishlist_items SET reserved_by = NULL, reserved_at = NULL WHERE id = $1 AND reserved_by = $2`, id, userID)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("не удалось снять бронь: %v", err))
+	}
+	return checkAffected(result, "вы не бронировали этот подарок")
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// AuditEvent - запись журнала аудита: кто (ActorID) что сделал (Type)
+// с каким пользователем (UserID). Нулевой ID означает, что участник неизвестен.
+type AuditEvent struct {
+	ID        int64     `json:"id" db:"id"`
+	Type      string    `json:"event_type" db:"event_type"`
+	ActorID   int64     `json:"actor_id" db:"actor_id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Details   string    `json:"details" db:"details"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// ActorName и UserName заполняются при чтении журнала.
+	ActorName string `json:"actor_name" db:"-"`
+	UserName  string `json:"user_name" db:"-"`
+}
+
+// AuditFilter - условия выборки журнала аудита. Пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	// UserID - события, где пользователь был автором или целью.
+	UserID int64
+	// Type - тип события целиком ("auth.login") или его категория ("auth").
+	Type  string
+	Limit int
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Channel - Telegram канал, созданный ботом. Key определяет назначение канала,
+// например общий канал поздравлений.
+type Channel struct {
+	Key        string `json:"key" db:"key"`
+	ChannelID  int64  `json:"channel_id" db:"channel_id"`
+	AccessHash int64  `json:"access_hash" db:"access_hash"`
+	// BotAdded - бот назначен администратором канала и может в нём писать.
+	BotAdded  bool      `json:"bot_added" db:"bot_added"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ChatID возвращает идентификатор канала в Bot API: MTProto ID канала с префиксом -100.
+func (c *Channel) ChatID() int64 {
+	return -1000000000000 - c.ChannelID
+}
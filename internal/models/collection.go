@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Collection - сбор денег на подарок ко дню рождения пользователя UserID.
+// Birthday - дата празднования, к которой открыт сбор.
+type Collection struct {
+	ID       int64     `json:"id" db:"id"`
+	UserID   int64     `json:"user_id" db:"user_id"`
+	Username string    `json:"username" db:"username"`
+	Birthday time.Time `json:"birthday" db:"birthday"`
+	// OrganizerID - пользователь, который собирает деньги. 0 - организатора пока нет.
+	OrganizerID   int64     `json:"organizer_id" db:"organizer_id"`
+	OrganizerName string    `json:"organizer_name" db:"organizer_name"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// CollectionPledge - сумма, которую участник обещал сдать на подарок.
+type CollectionPledge struct {
+	CollectionID int64  `json:"collection_id" db:"collection_id"`
+	UserID       int64  `json:"user_id" db:"user_id"`
+	Username     string `json:"username" db:"username"`
+	Amount       int    `json:"amount" db:"amount"`
+	// PaidAt - когда участник подтвердил оплату. Нулевое значение - ещё не оплачено.
+	PaidAt    time.Time `json:"paid_at" db:"paid_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DialogState - состояние многошаговой команды в чате: какая команда выполняется,
+// на каком она шаге и какие значения уже собраны.
+type DialogState struct {
+	ChatID    int64             `json:"chat_id" db:"chat_id"`
+	Command   string            `json:"command" db:"command"`
+	Step      int               `json:"step" db:"step"`
+	Data      map[string]string `json:"data" db:"data"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// LoginAttempt - счётчик неудачных попыток входа для одного ключа: Telegram ID
+// или имени пользователя. Scope указывает, что именно хранится в Key.
+type LoginAttempt struct {
+	Scope         string    `json:"scope" db:"scope"`
+	Key           string    `json:"key" db:"key"`
+	Failures      int       `json:"failures" db:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at" db:"last_failure_at"`
+	// LockedUntil - до какого момента вход запрещён. Нулевое значение - вход не заблокирован.
+	LockedUntil time.Time `json:"locked_until" db:"locked_until"`
+}
+
+// RecoveryCode - одноразовый код восстановления пароля. Хранится только хэш кода.
+type RecoveryCode struct {
+	UserID    int64     `json:"user_id" db:"user_id"`
+	CodeHash  string    `json:"code_hash" db:"code_hash"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Kind      string    `json:"kind" db:"kind"`
+	LocalDate time.Time `json:"local_date" db:"local_date"`
+	Message   string    `json:"message" db:"message"`
+	SentAt    time.Time `json:"sent_at" db:"sent_at"`
+}
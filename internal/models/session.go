@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Session struct {
+	ChatID     int64     `json:"chat_id" db:"chat_id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type Team struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedBy int64     `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// Members - количество участников, заполняется при получении списка команд.
+	Members int `json:"members" db:"members"`
+}
+
+// TeamMember - участник команды. Администраторы команды могут назначать
+// других администраторов и исключать участников.
+type TeamMember struct {
+	TeamID     int64     `json:"team_id" db:"team_id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	Username   string    `json:"username" db:"username"`
+	TelegramID int64     `json:"telegram_id" db:"telegram_id"`
+	Birthday   time.Time `json:"birthday" db:"birthday"`
+	Banned     bool      `json:"banned" db:"banned"`
+	IsAdmin    bool      `json:"is_admin" db:"is_admin"`
+	JoinedAt   time.Time `json:"joined_at" db:"joined_at"`
+}
@@ -2,16 +2,56 @@ package models
 
 import "time"
 
+// Role - роль пользователя. Роли упорядочены: каждая следующая включает права предыдущей.
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+	RoleOwner Role = "owner"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 2
+	case RoleAdmin:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast сообщает, есть ли у роли r права роли min.
+func (r Role) AtLeast(min Role) bool {
+	return r.rank() >= min.rank()
+}
+
+// Above сообщает, старше ли роль r роли other.
+func (r Role) Above(other Role) bool {
+	return r.rank() > other.rank()
+}
+
 type User struct {
 	ID         int64     `json:"id" db:"id"`
 	Username   string    `json:"username" db:"username"`
 	Password   string    `json:"password" db:"password"`
+	PIN        string    `json:"pin" db:"pin"` // хэш PIN-кода для входа без пароля, пустой - PIN не задан
 	TelegramID int64     `json:"telegram_id" db:"telegram_id"`
 	Birthday   time.Time `json:"birthday" db:"birthday"`
+	Timezone   string    `json:"timezone" db:"timezone"`
+	NotifyTime string    `json:"notify_time" db:"notify_time"`
+	Role       Role      `json:"role" db:"role"`
+	Banned     bool      `json:"banned" db:"banned"`
 }
 
 type UserBirthLayout struct {
+	ID         int64     `json:"id" db:"id"`
 	Username   string    `json:"username" db:"username"`
 	TelegramID int64     `json:"telegram_id" db:"telegram_id"`
 	Birthday   time.Time `json:"birthday" db:"birthday"`
+	Timezone   string    `json:"timezone" db:"timezone"`
+	NotifyTime string    `json:"notify_time" db:"notify_time"`
+	Role       Role      `json:"role" db:"role"`
+	Banned     bool      `json:"banned" db:"banned"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Wish - пожелание, которое AuthorID написал ко дню рождения UserID.
+// Birthday - дата празднования, в которую пожелание будет доставлено.
+type Wish struct {
+	UserID     int64     `json:"user_id" db:"user_id"`
+	AuthorID   int64     `json:"author_id" db:"author_id"`
+	AuthorName string    `json:"author_name" db:"author_name"`
+	Birthday   time.Time `json:"birthday" db:"birthday"`
+	Text       string    `json:"text" db:"text"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// WishlistItem - подарок из списка желаний пользователя UserID. Подписчики
+// могут забронировать подарок, владелец списка брони не видит.
+type WishlistItem struct {
+	ID     int64  `json:"id" db:"id"`
+	UserID int64  `json:"user_id" db:"user_id"`
+	Title  string `json:"title" db:"title"`
+	// ReservedBy - кто забронировал подарок. 0 - подарок свободен.
+	ReservedBy   int64     `json:"reserved_by" db:"reserved_by"`
+	ReservedName string    `json:"reserved_name" db:"reserved_name"`
+	ReservedAt   time.Time `json:"reserved_at" db:"reserved_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
@@ -1,12 +1,25 @@
 package notification
 
 import (
+	"BirthdayGreetings/internal/birthday"
 	"BirthdayGreetings/internal/bot"
+	"BirthdayGreetings/internal/channel"
+	"BirthdayGreetings/internal/collection"
+	"BirthdayGreetings/internal/db"
 	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/reminder"
 	"BirthdayGreetings/internal/service"
-	"BirthdayGreetings/internal/telegram"
+	"BirthdayGreetings/internal/subscription"
+	"BirthdayGreetings/internal/team"
+	"BirthdayGreetings/internal/wish"
+	"BirthdayGreetings/internal/wishlist"
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -14,23 +27,83 @@ import (
 
 type NotificationService struct {
 	botService      *bot.BotService
-	telegramService *telegram.Client
+	channels        *channel.ChannelService
+	teams           *team.TeamService
+	collections     *collection.CollectionService
+	wishes          *wish.WishService
+	wishlists       *wishlist.WishlistService
 	userService     *service.UserService
+	subService      *subscription.SubscriptionService
+	reminderService *reminder.ReminderService
+	history         db.NotificationRepository
+	matcher         *birthday.Matcher
 	cronScheduler   *cron.Cron
+	// idle - местная дата, на которую пользователю нечего напоминать. До
+	// следующего дня напоминания для него не собираются заново.
+	idleMu sync.Mutex
+	idle   map[int64]string
+	// ctx - контекст приложения из StartCronJobs: при остановке он отменяет
+	// уже начатые рассылки.
+	ctx context.Context
 }
 
-func NewNotificationService(userService *service.UserService, botService *bot.BotService, telegramService *telegram.Client) *NotificationService {
+// kindReminder - вид записи в журнале уведомлений для ежедневных напоминаний.
+const kindReminder = "reminder"
+
+// kindCollectionSummary - начало вида записи в журнале уведомлений для сводки
+// сбора организатору, за ним следует номер сбора.
+const kindCollectionSummary = "collection:"
+
+// kindWishes - вид записи в журнале уведомлений для доставки пожеланий имениннику.
+const kindWishes = "wishes"
+
+const (
+	channelKey   = channel.KeyBirthdays
+	channelTitle = "Поздравление с днем рождения"
+	channelAbout = "Канал для уведомления о днем рождении пользователей"
+
+	// teamChannelPrefix - начало ключа канала команды, за ним следует ID команды.
+	teamChannelPrefix = "team:"
+	teamChannelTitle  = "Дни рождения: %s"
+	teamChannelAbout  = "Канал команды %s для уведомления о днях рождения её участников"
+)
+
+func NewNotificationService(userService *service.UserService, subService *subscription.SubscriptionService, reminderService *reminder.ReminderService, history db.NotificationRepository, matcher *birthday.Matcher, botService *bot.BotService, channels *channel.ChannelService, teams *team.TeamService, collections *collection.CollectionService, wishes *wish.WishService, wishlists *wishlist.WishlistService) *NotificationService {
 	return &NotificationService{
 		userService:     userService,
+		subService:      subService,
+		reminderService: reminderService,
+		history:         history,
+		matcher:         matcher,
 		botService:      botService,
-		telegramService: telegramService,
+		channels:        channels,
+		teams:           teams,
+		collections:     collections,
+		wishes:          wishes,
+		wishlists:       wishlists,
 		cronScheduler:   cron.New(cron.WithSeconds()),
+		idle:            make(map[int64]string),
+		ctx:             context.Background(),
 	}
 }
 
-func (s *NotificationService) StartCronJobs() {
-	_, err := s.cronScheduler.AddFunc("0 0 9 * * *", func() {
-		s.handleDailyBirthdayNotifications()
+// StartCronJobs запускает расписание рассылок. Отмена ctx прерывает рассылки,
+// которые уже выполняются.
+func (s *NotificationService) StartCronJobs(ctx context.Context) {
+	s.ctx = ctx
+
+	// Напоминания проверяются каждую минуту: у каждого пользователя
+	// своё время уведомлений в его часовом поясе.
+	_, err := s.cronScheduler.AddFunc("0 * * * * *", func() {
+		s.sendDueReminders(time.Now())
+	})
+	if err != nil {
+		logging.Logger.Fatalf("Ошибка в установке уведомлений: %v", err)
+	}
+
+	_, err = s.cronScheduler.AddFunc("0 0 9 * * *", func() {
+		s.handleDailyChannelAnnouncement(ctx)
+		s.handleCollections(time.Now())
 	})
 	if err != nil {
 		logging.Logger.Fatalf("Ошибка в установке уведомлений: %v", err)
@@ -39,66 +112,534 @@ func (s *NotificationService) StartCronJobs() {
 	s.cronScheduler.Start()
 }
 
-func (s *NotificationService) handleDailyBirthdayNotifications() {
-	ctx := context.Background()
-
-	today := time.Now().Add(time.Hour * 24)
-	todayDate := fmt.Sprint(today.Day()) + " " + today.Month().String()
-	users, err := s.userService.GetUsersWithBirthday(today.Format("2000-01-02"))
+func (s *NotificationService) handleDailyChannelAnnouncement(ctx context.Context) {
+	today := time.Now()
+	users, err := s.userService.GetUsersWithBirthday(s.matcher.Keys(today))
 	if err != nil {
 		logging.Logger.Printf(err.Error())
 		return
 	}
 
-	channel, err := s.telegramService.CreateChannel(ctx, "Поздравление с днем рождения", "Канал для уведомления о днем рождении пользователей")
+	if len(users) == 0 {
+		logging.Logger.Println("Сегодня нет дней рождения.")
+	}
+
+	// Состав каналов синхронизируется каждый день, даже без дней рождения,
+	// чтобы удалённые и заблокированные пользователи не оставались в них.
+	channel := s.announceInChannel(ctx, users)
+	s.announceInTeamChannels(ctx, users)
+	s.deliverWishes(channel, today)
+}
+
+// sendDueReminders отправляет напоминания тем, у кого в их часовом поясе
+// наступило время уведомлений.
+func (s *NotificationService) sendDueReminders(now time.Time) {
+	if err := s.sendReminders(now, false); err != nil {
+		logging.Logger.Println(err.Error())
+	}
+}
+
+// RunDaily выполняет ежедневную рассылку вне расписания: напоминания всем
+// пользователям на их текущую дату, не дожидаясь их времени уведомлений,
+// и объявление в канале. Уже отправленные сегодня напоминания не повторяются.
+func (s *NotificationService) RunDaily() error {
+	if err := s.sendReminders(time.Now(), true); err != nil {
+		return err
+	}
+	s.handleDailyChannelAnnouncement(s.ctx)
+	s.handleCollections(time.Now())
+	return nil
+}
+
+// sendReminders за один проход по пользователям отправляет напоминания тем,
+// у кого в их часовом поясе уже наступило время уведомлений, или всем, если all.
+// Каждый подписчик получает одно личное сообщение со всеми напоминаниями на
+// свою текущую дату. Если время уведомлений пропущено, например бот был
+// остановлен, напоминание приходит при следующей проверке. Если пользователю
+// нечего напоминать, до конца его дня он пропускается, кроме запуска с all.
+// Заблокированным пользователям напоминания не отправляются.
+func (s *NotificationService) sendReminders(now time.Time, all bool) error {
+	users, err := s.userService.GetAllUsers()
 	if err != nil {
-		logging.Logger.Printf("Ошибка в создании канала: %v", err)
-		return
+		return fmt.Errorf("ошибка в получении пользователей: %w", err)
+	}
+
+	// Местная дата пользователя отличается от даты сервера не больше чем на сутки.
+	lastSent, err := s.history.GetLastNotified(kindReminder, now.AddDate(0, 0, -2))
+	if err != nil {
+		return fmt.Errorf("ошибка в получении журнала уведомлений: %w", err)
+	}
+
+	var allOffsets map[int64][]int
+	var allSubscriptions map[int64][]models.UserBirthLayout
+	var wishlistOwners map[int64]bool
+	sent := 0
+	for _, user := range users {
+		if user.Banned {
+			continue
+		}
+		local := now.In(service.Location(user.Timezone))
+		date := local.Format("2006-01-02")
+		if last, ok := lastSent[user.ID]; ok && last.Format("2006-01-02") == date {
+			continue
+		}
+		if !all && (local.Format("15:04") < notifyTime(user) || s.isIdle(user.ID, date)) {
+			continue
+		}
+
+		if allOffsets == nil {
+			allOffsets, err = s.reminderService.GetAllOffsets()
+			if err != nil {
+				return fmt.Errorf("ошибка в получении напоминаний: %w", err)
+			}
+			allSubscriptions, err = s.subService.GetAllSubscriptions()
+			if err != nil {
+				return fmt.Errorf("ошибка в получении подписок: %w", err)
+			}
+			// Без ссылок на списки желаний напоминания всё равно отправляются.
+			wishlistOwners, err = s.wishlists.Owners()
+			if err != nil {
+				logging.Logger.Printf("Ошибка в получении списков желаний: %v", err)
+			}
+		}
+
+		offsets, ok := allOffsets[user.ID]
+		if !ok {
+			offsets = reminder.DefaultOffsets
+		}
+
+		message := s.buildReminderMessage(allSubscriptions[user.ID], offsets, wishlistOwners, local)
+		if message == "" {
+			s.setIdle(user.ID, date)
+			continue
+		}
+
+		claimed, err := s.history.ClaimNotification(user.ID, kindReminder, local, message)
+		if err != nil {
+			logging.Logger.Printf("Ошибка в записи напоминания для %s: %v", user.Username, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.botService.SendMessage(user.TelegramID, message); err != nil {
+			logging.Logger.Printf("Ошибка в отправлении напоминания пользователю %s: %v", user.Username, err)
+			// Напоминание повторится при следующей проверке.
+			if err := s.history.ReleaseNotification(user.ID, kindReminder, local); err != nil {
+				logging.Logger.Printf("Ошибка в удалении напоминания для %s: %v", user.Username, err)
+			}
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logging.Logger.Printf("Отправлено напоминаний: %d", sent)
+	}
+	return nil
+}
+
+// isIdle сообщает, что пользователю userID нечего напоминать на местную дату date.
+func (s *NotificationService) isIdle(userID int64, date string) bool {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+	return s.idle[userID] == date
+}
+
+func (s *NotificationService) setIdle(userID int64, date string) {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+	s.idle[userID] = date
+}
+
+func notifyTime(user *models.UserBirthLayout) string {
+	if user.NotifyTime == "" {
+		return db.DefaultNotifyTime
+	}
+	return user.NotifyTime
+}
+
+// buildReminderMessage возвращает текст напоминаний подписчика с подписками
+// subscriptions на today или пустую строку, если напоминать не о чем.
+// wishlistOwners - пользователи с непустыми списками желаний.
+func (s *NotificationService) buildReminderMessage(subscriptions []models.UserBirthLayout, offsets []int, wishlistOwners map[int64]bool, today time.Time) string {
+	wanted := make(map[int]bool, len(offsets))
+	for _, days := range offsets {
+		wanted[days] = true
+	}
+
+	byDays := make(map[int][]string)
+	var upcoming []string
+	for _, sub := range subscriptions {
+		if !birthday.IsSet(sub.Birthday) {
+			continue
+		}
+		days := s.matcher.DaysUntil(sub.Birthday, today)
+		if !wanted[days] {
+			continue
+		}
+		byDays[days] = append(byDays[days], sub.Username)
+		if days > 0 {
+			upcoming = append(upcoming, sub.Username)
+		}
+	}
+
+	if len(byDays) == 0 {
+		return ""
+	}
+
+	days := make([]int, 0, len(byDays))
+	for d := range byDays {
+		days = append(days, d)
+	}
+	sort.Ints(days)
+
+	var lines []string
+	for _, d := range days {
+		names := strings.Join(byDays[d], ", ")
+		if d == 0 {
+			lines = append(lines, "Сегодня день рождения у "+names+" 🎉")
+			continue
+		}
+		date := today.AddDate(0, 0, d).Format("02.01")
+		lines = append(lines, fmt.Sprintf("Через %d дн. (%s) день рождения у %s", d, date, names))
+	}
+	lines = append(lines, s.wishlistLines(subscriptions, upcoming, wishlistOwners)...)
+
+	return strings.Join(lines, "\n")
+}
+
+// wishlistLines возвращает ссылки на списки желаний тех, о чьём дне рождения
+// напоминают заранее, если в их списках есть подарки.
+func (s *NotificationService) wishlistLines(subscriptions []models.UserBirthLayout, usernames []string, wishlistOwners map[int64]bool) []string {
+	ids := make(map[string]int64, len(subscriptions))
+	for _, sub := range subscriptions {
+		ids[sub.Username] = sub.ID
+	}
+
+	var lines []string
+	for _, username := range usernames {
+		if !wishlistOwners[ids[username]] {
+			continue
+		}
+		link := s.botService.WishlistLink(username)
+		if link == "" {
+			link = "/wishlist @" + username
+		}
+		lines = append(lines, "Список желаний "+username+": "+link)
+	}
+	return lines
+}
+
+// announceInChannel поздравляет именинников в общем канале и возвращает канал
+// или nil, если его не удалось получить. Канал создаётся один раз, при первом
+// дне рождения. Перед поздравлением, а также в дни без дней рождения в канал
+// приглашаются новые пользователи и исключаются удалённые и заблокированные.
+func (s *NotificationService) announceInChannel(ctx context.Context, birthdayUsers []models.UserBirthLayout) *models.Channel {
+	channel, err := s.channel(ctx, channelKey, channelTitle, channelAbout, len(birthdayUsers) > 0)
+	if err != nil {
+		logging.Logger.Println(err.Error())
+		return nil
+	}
+	if channel == nil {
+		return nil
 	}
 
 	allUsers, err := s.userService.GetAllUsers()
 	if err != nil {
 		logging.Logger.Printf(err.Error())
-		return
+		return nil
 	}
 
-	userTgIDs := make([]int64, 0, len(allUsers))
+	members := make([]int64, 0, len(allUsers))
 	for _, user := range allUsers {
-		if user.TelegramID != s.botService.GetAdminID() || fmt.Sprint(user.Birthday.Day())+" "+user.Birthday.Month().String() == todayDate {
-			userTgIDs = append(userTgIDs, user.TelegramID)
+		if !user.Banned {
+			members = append(members, user.TelegramID)
 		}
 	}
 
-	err = s.telegramService.AddUsersToChannel(ctx, channel, userTgIDs)
+	if err := s.channels.Sync(ctx, channel, members); err != nil {
+		logging.Logger.Printf("Ошибка в обновлении участников канала: %v", err)
+	}
+
+	if len(birthdayUsers) == 0 {
+		return channel
+	}
+
+	birthdayUsernames := make([]string, 0, len(birthdayUsers))
+	for _, user := range birthdayUsers {
+		birthdayUsernames = append(birthdayUsernames, user.Username)
+	}
+
+	if err := s.announce(ctx, channel, birthdayUsernames); err != nil {
+		logging.Logger.Printf("Ошибка в отправлении сообщения в канал: %v", err)
+		return channel
+	}
+
+	logging.Logger.Println("Уведомления успешно отправлено.")
+	return channel
+}
+
+// announceInTeamChannels поздравляет именинников в каналах их команд. У каждой
+// команды свой канал, он создаётся при первом дне рождения её участника.
+// В канал команды приглашаются только её незаблокированные участники, и в нём
+// объявляются только их дни рождения. Состав уже созданных каналов
+// синхронизируется и в дни без дней рождения.
+func (s *NotificationService) announceInTeamChannels(ctx context.Context, birthdayUsers []models.UserBirthLayout) {
+	teams, err := s.teams.List()
 	if err != nil {
-		logging.Logger.Printf("Ошибка в добавлении пользователей в канал: %v", err)
+		logging.Logger.Printf("Ошибка в получении команд: %v", err)
 		return
 	}
 
-	err = s.telegramService.AddBotToChannel(ctx, channel, s.botService.GetBotID())
+	today := make(map[int64]bool, len(birthdayUsers))
+	for _, user := range birthdayUsers {
+		today[user.ID] = true
+	}
+
+	for _, t := range teams {
+		if ctx.Err() != nil {
+			return
+		}
+		members, err := s.teams.Members(t.ID)
+		if err != nil {
+			logging.Logger.Printf("Ошибка в получении участников команды %s: %v", t.Name, err)
+			continue
+		}
+
+		var birthdayUsernames []string
+		telegramIDs := make([]int64, 0, len(members))
+		for _, m := range members {
+			if m.Banned {
+				continue
+			}
+			telegramIDs = append(telegramIDs, m.TelegramID)
+			if today[m.UserID] {
+				birthdayUsernames = append(birthdayUsernames, m.Username)
+			}
+		}
+
+		key := teamChannelPrefix + strconv.FormatInt(t.ID, 10)
+		channel, err := s.channel(ctx, key, fmt.Sprintf(teamChannelTitle, t.Name), fmt.Sprintf(teamChannelAbout, t.Name), len(birthdayUsernames) > 0)
+		if err != nil {
+			logging.Logger.Printf("Ошибка в получении канала команды %s: %v", t.Name, err)
+			continue
+		}
+		if channel == nil {
+			continue
+		}
+		if err := s.channels.Sync(ctx, channel, telegramIDs); err != nil {
+			logging.Logger.Printf("Ошибка в обновлении участников канала команды %s: %v", t.Name, err)
+		}
+		if len(birthdayUsernames) == 0 {
+			continue
+		}
+
+		if err := s.announce(ctx, channel, birthdayUsernames); err != nil {
+			logging.Logger.Printf("Ошибка в отправлении сообщения в канал команды %s: %v", t.Name, err)
+			continue
+		}
+		logging.Logger.Printf("Уведомление отправлено в канал команды %s.", t.Name)
+	}
+}
+
+// channel возвращает канал key. Если create, канал создаётся при необходимости,
+// иначе возвращается только уже созданный канал или nil.
+func (s *NotificationService) channel(ctx context.Context, key, title, about string, create bool) (*models.Channel, error) {
+	if create {
+		return s.channels.Ensure(ctx, key, title, about)
+	}
+	return s.channels.Existing(ctx, key)
+}
+
+func (s *NotificationService) announce(ctx context.Context, channel *models.Channel, usernames []string) error {
+	message := "Сегодня день рождение у " + strings.Join(usernames, ", ") + " 🎉"
+	return s.botService.SendMessageToChannel(ctx, channel.ChatID(), message)
+}
+
+// deliverWishes отправляет именинникам пожелания ко всем дням рождения до
+// today включительно и публикует их в общем канале, если он есть. Если
+// рассылка пропустила день рождения, пожелания доставятся при следующем
+// запуске. Пожелания доставляются один раз, даже если рассылка запущена
+// повторно, и после доставки удаляются.
+func (s *NotificationService) deliverWishes(channel *models.Channel, today time.Time) {
+	pending, err := s.wishes.Pending(today)
 	if err != nil {
-		logging.Logger.Printf("Ошибка в добавлении бота в канал: %v", err)
+		logging.Logger.Printf("Ошибка в получении пожеланий: %v", err)
 		return
 	}
 
-	if len(users) > 0 {
-		birthdayUsernames := ""
-		for _, user := range users {
-			birthdayUsernames += user.Username + ", "
+	for len(pending) > 0 {
+		// Пожелания сгруппированы по имениннику и дате дня рождения.
+		n := 1
+		for n < len(pending) && pending[n].UserID == pending[0].UserID && pending[n].Birthday.Format("2006-01-02") == pending[0].Birthday.Format("2006-01-02") {
+			n++
 		}
-		birthdayUsernames = birthdayUsernames[:len(birthdayUsernames)-2]
+		wishes := pending[:n]
+		pending = pending[n:]
 
-		message := "Сегодня день рождение у " + birthdayUsernames + " 🎉"
-		err = s.botService.SendMessageToChannel(ctx, channel.ID, message)
+		user, err := s.userService.GetUserByID(wishes[0].UserID)
 		if err != nil {
-			logging.Logger.Printf("Ошибка в отправлении сообщения в канал: %v", err)
-			return
+			logging.Logger.Printf("Ошибка в получении именинника %d: %v", wishes[0].UserID, err)
+			continue
+		}
+		if user.Banned {
+			continue
+		}
+
+		date := wishes[0].Birthday
+		claimed, err := s.history.ClaimNotification(user.ID, kindWishes, date, fmt.Sprintf("пожеланий: %d", len(wishes)))
+		if err != nil {
+			logging.Logger.Printf("Ошибка в записи пожеланий для %s: %v", user.Username, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.sendWishes(channel, user, date, wishes); err != nil {
+			logging.Logger.Printf("Ошибка в отправлении пожеланий пользователю %s: %v", user.Username, err)
+			// Пожелания доставятся при следующем запуске рассылки.
+			if err := s.history.ReleaseNotification(user.ID, kindWishes, date); err != nil {
+				logging.Logger.Printf("Ошибка в удалении записи о пожеланиях для %s: %v", user.Username, err)
+			}
+			continue
+		}
+		if err := s.wishes.Delivered(user.ID, date); err != nil {
+			logging.Logger.Printf("Ошибка в удалении доставленных пожеланий для %s: %v", user.Username, err)
 		}
 	}
+}
 
-	logging.Logger.Println("Уведомления успешно отправлено.")
+// DeliverWish доставляет пожелание, написанное в сам день рождения, так же,
+// как ежедневная рассылка: имениннику и в общий канал, если он есть.
+func (s *NotificationService) DeliverWish(w models.Wish) error {
+	user, err := s.userService.GetUserByID(w.UserID)
+	if err != nil {
+		return err
+	}
+
+	channel, err := s.channel(s.ctx, channelKey, channelTitle, channelAbout, false)
+	if err != nil {
+		logging.Logger.Println(err.Error())
+	}
+	return s.sendWishes(channel, user, w.Birthday, []models.Wish{w})
 }
 
-func (s *NotificationService) StopCronJobs() {
-	s.cronScheduler.Stop()
+// sendWishes отправляет пожелания имениннику и, если он их получил, публикует
+// их в канале channel. Ошибка публикации только записывается в журнал.
+func (s *NotificationService) sendWishes(channel *models.Channel, user *models.User, date time.Time, wishes []models.Wish) error {
+	if err := s.botService.SendWishes(user.TelegramID, user.Username, date, wishes); err != nil {
+		return err
+	}
+	if channel == nil {
+		return nil
+	}
+	if err := s.botService.SendWishes(channel.ChatID(), user.Username, date, wishes); err != nil {
+		logging.Logger.Printf("Ошибка в публикации пожеланий для %s в канале: %v", user.Username, err)
+	}
+	return nil
+}
+
+// handleCollections открывает сборы на подарки к дням рождения, до которых
+// осталось не больше DaysBefore дней, и рассылает участникам приглашения, а
+// за день до дня рождения отправляет организаторам сводку. Если рассылка
+// пропустила день, сбор откроется при следующем запуске. Сбор к одной дате
+// создаётся один раз, поэтому приглашения повторно не отправляются.
+func (s *NotificationService) handleCollections(now time.Time) {
+	days := s.collections.DaysBefore()
+	if days <= 0 {
+		return
+	}
+
+	users, err := s.userService.GetAllUsers()
+	if err != nil {
+		logging.Logger.Printf("Ошибка в получении пользователей: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		if user.Banned || !birthday.IsSet(user.Birthday) {
+			continue
+		}
+		if d := s.matcher.DaysUntil(user.Birthday, now); d > days || d == 0 {
+			continue
+		}
+		s.openCollection(*user, s.matcher.Next(user.Birthday, now))
+	}
+
+	s.sendCollectionSummaries(now)
+}
+
+func (s *NotificationService) openCollection(user models.UserBirthLayout, date time.Time) {
+	c, participants, err := s.collections.Open(user, date)
+	if err != nil {
+		logging.Logger.Printf("Ошибка в открытии сбора для %s: %v", user.Username, err)
+		return
+	}
+	if c == nil || len(participants) == 0 {
+		return
+	}
+
+	for _, participant := range participants {
+		if err := s.botService.SendCollectionInvite(participant.TelegramID, c); err != nil {
+			logging.Logger.Printf("Ошибка в отправлении приглашения в сбор пользователю %s: %v", participant.Username, err)
+		}
+	}
+	logging.Logger.Printf("Открыт сбор №%d для %s, участников: %d", c.ID, user.Username, len(participants))
+}
+
+// sendCollectionSummaries отправляет организаторам сводку сборов к завтрашним
+// дням рождения. Если рассылка накануне пропущена, сводка приходит в сам день
+// рождения. Сводка каждого сбора отправляется один раз.
+func (s *NotificationService) sendCollectionSummaries(now time.Time) {
+	collections, err := s.collections.Active(now)
+	if err != nil {
+		logging.Logger.Printf("Ошибка в получении сборов: %v", err)
+		return
+	}
+
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+	for _, c := range collections {
+		if c.OrganizerID == 0 || c.Birthday.Format("2006-01-02") > tomorrow {
+			continue
+		}
+
+		organizer, err := s.userService.GetUserByID(c.OrganizerID)
+		if err != nil {
+			logging.Logger.Printf("Ошибка в поиске организатора сбора %d: %v", c.ID, err)
+			continue
+		}
+		kind := kindCollectionSummary + strconv.FormatInt(c.ID, 10)
+		// Запись привязана к дате дня рождения, а не к дате отправки, чтобы
+		// сводку не прислали повторно в сам день рождения.
+		claimed, err := s.history.ClaimNotification(organizer.ID, kind, c.Birthday, fmt.Sprintf("сводка сбора №%d", c.ID))
+		if err != nil {
+			logging.Logger.Printf("Ошибка в записи сводки сбора %d: %v", c.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.botService.SendCollectionSummary(organizer.TelegramID, c.ID); err != nil {
+			logging.Logger.Printf("Ошибка в отправлении сводки сбора %d: %v", c.ID, err)
+			// Сводка повторится при следующем запуске рассылки.
+			if err := s.history.ReleaseNotification(organizer.ID, kind, c.Birthday); err != nil {
+				logging.Logger.Printf("Ошибка в удалении записи о сводке сбора %d: %v", c.ID, err)
+			}
+		}
+	}
+}
+
+// StopCronJobs останавливает планировщик и ждёт завершения уже запущенных
+// рассылок, но не дольше, чем позволяет ctx.
+func (s *NotificationService) StopCronJobs(ctx context.Context) error {
+	select {
+	case <-s.cronScheduler.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,94 @@
+package reminder
+
+import (
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// MaxDaysBefore - максимальное количество дней до дня рождения для напоминания.
+const MaxDaysBefore = 365
+
+// MaxOffsets ограничивает количество напоминаний у одного пользователя.
+const MaxOffsets = 5
+
+// DefaultOffsets используется для пользователей, которые не настраивали напоминания:
+// уведомление приходит только в сам день рождения.
+var DefaultOffsets = []int{0}
+
+type ReminderService struct {
+	repo db.ReminderRepository
+}
+
+func NewReminderService(repo db.ReminderRepository) *ReminderService {
+	return &ReminderService{repo: repo}
+}
+
+// GetOffsets возвращает за сколько дней до дня рождения пользователь получает напоминания.
+func (s *ReminderService) GetOffsets(userID int64) ([]int, error) {
+	offsets, err := s.repo.GetReminderOffsets(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(offsets) == 0 {
+		return DefaultOffsets, nil
+	}
+	return offsets, nil
+}
+
+// GetAllOffsets возвращает напоминания всех пользователей. Пользователей
+// с напоминаниями по умолчанию в результате нет.
+func (s *ReminderService) GetAllOffsets() (map[int64][]int, error) {
+	return s.repo.GetAllReminderOffsets()
+}
+
+func (s *ReminderService) SetOffsets(userID int64, offsets []int) error {
+	if len(offsets) == 0 {
+		return errors.New(400, "укажите хотя бы одно напоминание")
+	}
+	if len(offsets) > MaxOffsets {
+		return errors.New(400, fmt.Sprintf("можно указать не больше %d напоминаний", MaxOffsets))
+	}
+
+	unique := make(map[int]bool)
+	for _, days := range offsets {
+		if days < 0 || days > MaxDaysBefore {
+			return errors.New(400, fmt.Sprintf("количество дней должно быть от 0 до %d", MaxDaysBefore))
+		}
+		unique[days] = true
+	}
+
+	normalized := make([]int, 0, len(unique))
+	for days := range unique {
+		normalized = append(normalized, days)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(normalized)))
+
+	return s.repo.SetReminderOffsets(userID, normalized)
+}
+
+// ParseOffsets разбирает список дней вида "7 3 0".
+func ParseOffsets(args []string) ([]int, error) {
+	offsets := make([]int, 0, len(args))
+	for _, arg := range args {
+		if arg == "" {
+			continue
+		}
+		days, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, errors.New(400, fmt.Sprintf("некорректное количество дней: %s", arg))
+		}
+		offsets = append(offsets, days)
+	}
+	return offsets, nil
+}
+
+// FormatOffset возвращает описание напоминания для пользователя.
+func FormatOffset(days int) string {
+	if days == 0 {
+		return "в день рождения"
+	}
+	return fmt.Sprintf("за %d дн.", days)
+}
@@ -1,44 +1,176 @@
 package service
 
 import (
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/birthday"
 	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
 	"BirthdayGreetings/internal/models"
+	"fmt"
+	"sync"
+	"time"
 )
 
-type UserService struct{}
+type UserService struct {
+	repo  db.UserRepository
+	audit *audit.AuditService
+}
 
-func NewUserService() *UserService {
-	return &UserService{}
+func NewUserService(repo db.UserRepository, auditService *audit.AuditService) *UserService {
+	return &UserService{repo: repo, audit: auditService}
 }
 
 func (s *UserService) CreateUser(user *models.User) error {
-	return db.CreateUser(user)
+	return s.repo.CreateUser(user)
 }
 
 func (s *UserService) GetAllUsers() ([]*models.UserBirthLayout, error) {
-	users, err := db.GetAllUsers()
+	users, err := s.repo.GetAllUsers()
 	return users, err
 }
 
-func (s *UserService) SetUserBirthday(telegramID int64, birthday string) error {
-	return db.SetUserBirthday(telegramID, birthday)
+func (s *UserService) SetUserBirthday(telegramID int64, date string) error {
+	user, err := s.repo.GetUserByTgID(telegramID)
+	if err != nil {
+		return err
+	}
+	if err := s.ChangeBirthday(user, date); err != nil {
+		return err
+	}
+
+	previous := "-"
+	if birthday.IsSet(user.Birthday) {
+		previous = user.Birthday.Format("2006-01-02")
+	}
+	s.audit.Record(audit.EventBirthdayChanged, user.ID, user.ID, previous+" -> "+date)
+	return nil
+}
+
+// ChangeBirthday сохраняет дату рождения user в формате YYYY-MM-DD, не записывая
+// событие самого пользователя. Его использует AdminService, который записывает
+// своё событие администратора.
+func (s *UserService) ChangeBirthday(user *models.User, date string) error {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return errors.New(400, fmt.Sprintf("неверный формат даты: %s", date))
+	}
+	return s.repo.SetUserBirthday(user.TelegramID, date)
+}
+
+// SetUserTimezone сохраняет часовой пояс пользователя в формате IANA, например Europe/Moscow.
+func (s *UserService) SetUserTimezone(telegramID int64, timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || timezone == "Local" {
+		return errors.New(400, fmt.Sprintf("неизвестный часовой пояс: %s", timezone))
+	}
+
+	user, err := s.repo.GetUserByTgID(telegramID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetUserTimezone(telegramID, timezone); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventTimezoneChanged, user.ID, user.ID, orDash(user.Timezone)+" -> "+timezone)
+	return nil
+}
+
+// SetUserNotifyTime сохраняет время в формате HH:MM, в которое пользователь получает уведомления.
+func (s *UserService) SetUserNotifyTime(telegramID int64, notifyTime string) error {
+	parsed, err := time.Parse("15:04", notifyTime)
+	if err != nil {
+		return errors.New(400, fmt.Sprintf("неверный формат времени: %s", notifyTime))
+	}
+	notifyTime = parsed.Format("15:04")
+
+	user, err := s.repo.GetUserByTgID(telegramID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetUserNotifyTime(telegramID, notifyTime); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventNotifyTimeChanged, user.ID, user.ID, orDash(user.NotifyTime)+" -> "+notifyTime)
+	return nil
+}
+
+func (s *UserService) SetUserRole(userID int64, role models.Role) error {
+	switch role {
+	case models.RoleUser, models.RoleAdmin, models.RoleOwner:
+	default:
+		return errors.New(400, fmt.Sprintf("неизвестная роль: %s", role))
+	}
+	return s.repo.SetUserRole(userID, role)
+}
+
+func (s *UserService) SetUserBanned(userID int64, banned bool) error {
+	return s.repo.SetUserBanned(userID, banned)
+}
+
+// SetUserPassword сохраняет хэш нового пароля пользователя.
+func (s *UserService) SetUserPassword(userID int64, passwordHash string) error {
+	return s.repo.SetUserPassword(userID, passwordHash)
+}
+
+// SetUserPIN сохраняет хэш PIN-кода пользователя. Пустая строка удаляет PIN.
+func (s *UserService) SetUserPIN(userID int64, pinHash string) error {
+	return s.repo.SetUserPIN(userID, pinHash)
+}
+
+// DeleteUser удаляет пользователя вместе со всеми его данными.
+func (s *UserService) DeleteUser(userID int64) error {
+	return s.repo.DeleteUser(userID)
 }
 
 func (s *UserService) UpdateUser(user *models.User) error {
-	return db.UpdateUser(user)
+	return s.repo.UpdateUser(user)
+}
+
+func (s *UserService) GetUserByID(userID int64) (*models.User, error) {
+	return s.repo.GetUserByID(userID)
 }
 
 func (s *UserService) GetUserByName(username string) (*models.User, error) {
-	users, err := db.GetUserByName(username)
+	users, err := s.repo.GetUserByName(username)
 	return users, err
 }
 
 func (s *UserService) GetUserByTgID(telegramID int64) (*models.User, error) {
-	users, err := db.GetUserByTgID(telegramID)
+	users, err := s.repo.GetUserByTgID(telegramID)
 	return users, err
 }
 
-func (s *UserService) GetUsersWithBirthday(date string) ([]models.UserBirthLayout, error) {
-	users, err := db.GetUsersWithBirthday(date)
+// GetUsersWithBirthday возвращает пользователей, чья дата рождения в формате MM-DD входит в dates.
+// Список дат для конкретного дня строит birthday.Matcher.
+func (s *UserService) GetUsersWithBirthday(dates []string) ([]models.UserBirthLayout, error) {
+	users, err := s.repo.GetUsersWithBirthday(dates)
 	return users, err
 }
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// locations хранит уже загруженные часовые пояса по имени: Location
+// вызывается для каждого пользователя при каждой проверке напоминаний.
+var locations sync.Map
+
+// Location возвращает часовой пояс пользователя. Для пустого или
+// неизвестного значения используется часовой пояс сервера.
+func Location(timezone string) *time.Location {
+	if timezone == "" {
+		return time.Local
+	}
+	if loc, ok := locations.Load(timezone); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	locations.Store(timezone, loc)
+	return loc
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/models"
+)
+
+func newTestUserService(t *testing.T) (*UserService, *models.User) {
+	t.Helper()
+	users := db.NewMemoryUserRepository()
+	s := NewUserService(users, audit.NewAuditService(db.NewMemoryAuditRepository(users)))
+
+	user := &models.User{Username: "alice", TelegramID: 100}
+	if err := s.CreateUser(user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	return s, user
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    models.User
+		wantErr bool
+	}{
+		{"новый пользователь", models.User{Username: "bob", TelegramID: 200}, false},
+		{"занятое имя", models.User{Username: "alice", TelegramID: 200}, true},
+		{"занятый Telegram аккаунт", models.User{Username: "bob", TelegramID: 100}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestUserService(t)
+			err := s.CreateUser(&tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := s.GetUserByName(tt.user.Username)
+			if err != nil {
+				t.Fatalf("GetUserByName() error = %v", err)
+			}
+			if got.NotifyTime != db.DefaultNotifyTime || got.Role != models.RoleUser {
+				t.Errorf("новый пользователь: время %q, роль %q, want %q, %q", got.NotifyTime, got.Role, db.DefaultNotifyTime, models.RoleUser)
+			}
+		})
+	}
+}
+
+func TestGetUserByID(t *testing.T) {
+	s, user := newTestUserService(t)
+	tests := []struct {
+		name    string
+		id      int64
+		wantErr bool
+	}{
+		{"существующий", user.ID, false},
+		{"неизвестный", user.ID + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetUserByID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetUserByID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Username != user.Username {
+				t.Errorf("GetUserByID() = %s, want %s", got.Username, user.Username)
+			}
+		})
+	}
+}
+
+func TestSetUserBirthday(t *testing.T) {
+	tests := []struct {
+		name       string
+		telegramID int64
+		date       string
+		wantErr    bool
+	}{
+		{"верная дата", 100, "1990-05-17", false},
+		{"29 февраля", 100, "2000-02-29", false},
+		{"неверный формат", 100, "17.05.1990", true},
+		{"несуществующая дата", 100, "1990-02-30", true},
+		{"неизвестный пользователь", 999, "1990-05-17", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, user := newTestUserService(t)
+			err := s.SetUserBirthday(tt.telegramID, tt.date)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetUserBirthday() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := s.GetUserByTgID(user.TelegramID)
+			if err != nil {
+				t.Fatalf("GetUserByTgID() error = %v", err)
+			}
+			want := ""
+			if !tt.wantErr {
+				want = tt.date
+			}
+			if gotDate := formatDate(got.Birthday); gotDate != want {
+				t.Errorf("дата рождения = %q, want %q", gotDate, want)
+			}
+		})
+	}
+}
+
+func TestSetUserTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		wantErr  bool
+	}{
+		{"часовой пояс IANA", "Europe/Moscow", false},
+		{"UTC", "UTC", false},
+		{"пустой", "", true},
+		{"Local", "Local", true},
+		{"неизвестный", "Mars/Olympus", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, user := newTestUserService(t)
+			err := s.SetUserTimezone(user.TelegramID, tt.timezone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetUserTimezone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, _ := s.GetUserByTgID(user.TelegramID)
+			want := ""
+			if !tt.wantErr {
+				want = tt.timezone
+			}
+			if got.Timezone != want {
+				t.Errorf("часовой пояс = %q, want %q", got.Timezone, want)
+			}
+		})
+	}
+}
+
+func TestSetUserNotifyTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"ЧЧ:ММ", "18:30", "18:30", false},
+		{"час одной цифрой", "7:05", "07:05", false},
+		{"неверный час", "25:00", db.DefaultNotifyTime, true},
+		{"неверный формат", "вечером", db.DefaultNotifyTime, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, user := newTestUserService(t)
+			err := s.SetUserNotifyTime(user.TelegramID, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetUserNotifyTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, _ := s.GetUserByTgID(user.TelegramID)
+			if got.NotifyTime != tt.want {
+				t.Errorf("время уведомлений = %q, want %q", got.NotifyTime, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetUserRole(t *testing.T) {
+	tests := []struct {
+		role    models.Role
+		wantErr bool
+	}{
+		{models.RoleUser, false},
+		{models.RoleAdmin, false},
+		{models.RoleOwner, false},
+		{"root", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			s, user := newTestUserService(t)
+			if err := s.SetUserRole(user.ID, tt.role); (err != nil) != tt.wantErr {
+				t.Fatalf("SetUserRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetUsersWithBirthday(t *testing.T) {
+	s, user := newTestUserService(t)
+	if err := s.SetUserBirthday(user.TelegramID, "1990-05-17"); err != nil {
+		t.Fatalf("SetUserBirthday() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		dates []string
+		want  int
+	}{
+		{"совпадает", []string{"05-17"}, 1},
+		{"одна из дат", []string{"02-28", "05-17"}, 1},
+		{"не совпадает", []string{"05-18"}, 0},
+		{"без дат", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetUsersWithBirthday(tt.dates)
+			if err != nil {
+				t.Fatalf("GetUsersWithBirthday() error = %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("GetUsersWithBirthday() = %d пользователей, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"Europe/Moscow", "Europe/Moscow"},
+		{"", time.Local.String()},
+		{"Mars/Olympus", time.Local.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			if got := Location(tt.timezone).String(); got != tt.want {
+				t.Errorf("Location() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func formatDate(date time.Time) string {
+	if !birthday.IsSet(date) {
+		return ""
+	}
+	return date.Format("2006-01-02")
+}
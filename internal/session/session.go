@@ -0,0 +1,166 @@
+package session
+
+import (
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/logging"
+	"BirthdayGreetings/internal/models"
+	"context"
+	"time"
+)
+
+type SessionService struct {
+	repo            db.SessionRepository
+	idleTimeout     time.Duration
+	absoluteTimeout time.Duration
+}
+
+// NewSessionService создаёт сервис сессий. Сессия истекает, если пользователь
+// не пользовался ботом дольше idleTimeout или с момента входа прошло больше
+// absoluteTimeout. Нулевое значение отключает соответствующее ограничение.
+func NewSessionService(repo db.SessionRepository, idleTimeout, absoluteTimeout time.Duration) *SessionService {
+	return &SessionService{
+		repo:            repo,
+		idleTimeout:     idleTimeout,
+		absoluteTimeout: absoluteTimeout,
+	}
+}
+
+func (s *SessionService) Login(chatID, userID int64) error {
+	now := time.Now()
+	return s.repo.CreateSession(&models.Session{
+		ChatID:     chatID,
+		UserID:     userID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	})
+}
+
+func (s *SessionService) Logout(chatID int64) error {
+	return s.repo.DeleteSession(chatID)
+}
+
+// IsLoggedIn проверяет, есть ли в чате действующая сессия, и продлевает её.
+// Истёкшая сессия удаляется.
+func (s *SessionService) IsLoggedIn(chatID int64) bool {
+	session, err := s.repo.GetSession(chatID)
+	if err != nil {
+		return false
+	}
+
+	now := time.Now()
+	if s.expired(session, now) {
+		if err := s.repo.DeleteSession(chatID); err != nil {
+			logging.Logger.Printf("Ошибка в удалении истёкшей сессии %d: %v", chatID, err)
+		}
+		return false
+	}
+
+	if err := s.repo.TouchSession(chatID, now); err != nil {
+		logging.Logger.Printf("Ошибка в обновлении сессии %d: %v", chatID, err)
+	}
+	return true
+}
+
+// GetUserSessions возвращает действующие сессии пользователя.
+func (s *SessionService) GetUserSessions(userID int64) ([]models.Session, error) {
+	sessions, err := s.repo.GetUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := sessions[:0]
+	for _, session := range sessions {
+		if !s.expired(&session, now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// Revoke завершает сессию chatID, если она принадлежит пользователю userID.
+func (s *SessionService) Revoke(userID, chatID int64) error {
+	session, err := s.repo.GetSession(chatID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return errors.New(404, "сессия не найдена")
+	}
+	return s.repo.DeleteSession(chatID)
+}
+
+// RevokeOthers завершает все сессии пользователя, кроме сессии в чате keepChatID,
+// и возвращает количество завершённых.
+func (s *SessionService) RevokeOthers(userID, keepChatID int64) (int, error) {
+	sessions, err := s.repo.GetUserSessions(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ChatID == keepChatID {
+			continue
+		}
+		if err := s.repo.DeleteSession(session.ChatID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// RevokeAll завершает все сессии пользователя и возвращает их количество.
+func (s *SessionService) RevokeAll(userID int64) (int, error) {
+	return s.RevokeOthers(userID, 0)
+}
+
+// Cleanup удаляет все истёкшие сессии.
+func (s *SessionService) Cleanup() (int64, error) {
+	var idleBefore, createdBefore time.Time
+	now := time.Now()
+	if s.idleTimeout > 0 {
+		idleBefore = now.Add(-s.idleTimeout)
+	}
+	if s.absoluteTimeout > 0 {
+		createdBefore = now.Add(-s.absoluteTimeout)
+	}
+	if idleBefore.IsZero() && createdBefore.IsZero() {
+		return 0, nil
+	}
+	return s.repo.DeleteExpiredSessions(idleBefore, createdBefore)
+}
+
+// RunCleanup периодически удаляет истёкшие сессии, пока не будет отменён ctx.
+func (s *SessionService) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Cleanup()
+			if err != nil {
+				logging.Logger.Printf("Ошибка в очистке сессий: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				logging.Logger.Printf("Удалено истёкших сессий: %d", deleted)
+			}
+		}
+	}
+}
+
+func (s *SessionService) expired(session *models.Session, now time.Time) bool {
+	if s.idleTimeout > 0 && now.Sub(session.LastSeenAt) > s.idleTimeout {
+		return true
+	}
+	if s.absoluteTimeout > 0 && now.Sub(session.CreatedAt) > s.absoluteTimeout {
+		return true
+	}
+	return false
+}
@@ -1,14 +1,18 @@
 package subscription
 
 import (
+	"BirthdayGreetings/internal/audit"
 	"BirthdayGreetings/internal/db"
 	"BirthdayGreetings/internal/models"
 )
 
-type SubscriptionService struct{}
+type SubscriptionService struct {
+	repo  db.SubscriptionRepository
+	audit *audit.AuditService
+}
 
-func NewSubscriptionService() *SubscriptionService {
-	return &SubscriptionService{}
+func NewSubscriptionService(repo db.SubscriptionRepository, auditService *audit.AuditService) *SubscriptionService {
+	return &SubscriptionService{repo: repo, audit: auditService}
 }
 
 func (s *SubscriptionService) SubscribeUser(subscriberID, subscribedUserID int64) error {
@@ -17,20 +21,42 @@ func (s *SubscriptionService) SubscribeUser(subscriberID, subscribedUserID int64
 		SubscriberID:     subscriberID,
 		SubscribedUserID: subscribedUserID,
 	}
-	return db.CreateSubscription(sub)
+	if err := s.repo.CreateSubscription(sub); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventSubscribed, subscriberID, subscribedUserID, "")
+	return nil
 }
 
 func (s *SubscriptionService) UnsubscribeUser(subscriberID, subscribedUserID int64) error {
-	return db.DeleteSubscription(subscriberID, subscribedUserID)
+	if err := s.repo.DeleteSubscription(subscriberID, subscribedUserID); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventUnsubscribed, subscriberID, subscribedUserID, "")
+	return nil
 }
 
 func (s *SubscriptionService) GetSubscriptions(userID int64) ([]models.UserBirthLayout, error) {
-	subscriptions, err := db.GetSubscribers(userID)
+	subscriptions, err := s.repo.GetSubscribers(userID)
 	return subscriptions, err
 }
 
+// GetAllSubscriptions возвращает подписки всех пользователей одним запросом:
+// ключ - ID подписчика, значение - пользователи, на которых он подписан.
+func (s *SubscriptionService) GetAllSubscriptions() (map[int64][]models.UserBirthLayout, error) {
+	return s.repo.GetAllSubscriptions()
+}
+
+// GetSubscribersOf возвращает пользователей, подписанных на дни рождения userID.
+func (s *SubscriptionService) GetSubscribersOf(userID int64) ([]models.UserBirthLayout, error) {
+	subscribers, err := s.repo.GetSubscribersOf(userID)
+	return subscribers, err
+}
+
 func (s *SubscriptionService) IsSubscribed(subscriberID, subscribedUserID int64) (bool, error) {
-	result, err := db.IsSubscribed(subscriberID, subscribedUserID)
+	result, err := s.repo.IsSubscribed(subscriberID, subscribedUserID)
 	if err != nil {
 		return false, err
 	}
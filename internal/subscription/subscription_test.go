@@ -0,0 +1,197 @@
+package subscription
+
+import (
+	"sort"
+	"testing"
+
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/models"
+)
+
+type testUsers struct {
+	repo *db.MemoryUserRepository
+	ids  map[string]int64
+}
+
+// newTestSubscriptionService создаёт сервис подписок в памяти с пользователями alice, bob и carol.
+func newTestSubscriptionService(t *testing.T) (*SubscriptionService, testUsers) {
+	t.Helper()
+	users := testUsers{repo: db.NewMemoryUserRepository(), ids: make(map[string]int64)}
+	for i, name := range []string{"alice", "bob", "carol"} {
+		user := &models.User{Username: name, TelegramID: int64(100 + i)}
+		if err := users.repo.CreateUser(user); err != nil {
+			t.Fatalf("CreateUser(%s) error = %v", name, err)
+		}
+		users.ids[name] = user.ID
+	}
+
+	auditService := audit.NewAuditService(db.NewMemoryAuditRepository(users.repo))
+	return NewSubscriptionService(db.NewMemorySubscriptionRepository(users.repo), auditService), users
+}
+
+func TestSubscribeUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   [][2]string
+		subscriber string
+		target     string
+		wantErr    bool
+	}{
+		{"новая подписка", nil, "alice", "bob", false},
+		{"встречная подписка", [][2]string{{"bob", "alice"}}, "alice", "bob", false},
+		{"повторная подписка", [][2]string{{"alice", "bob"}}, "alice", "bob", true},
+		{"неизвестный пользователь", nil, "alice", "dave", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, users := newTestSubscriptionService(t)
+			for _, sub := range tt.existing {
+				if err := s.SubscribeUser(users.ids[sub[0]], users.ids[sub[1]]); err != nil {
+					t.Fatalf("SubscribeUser(%s, %s) error = %v", sub[0], sub[1], err)
+				}
+			}
+
+			err := s.SubscribeUser(users.ids[tt.subscriber], users.ids[tt.target])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SubscribeUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			subscribed, err := s.IsSubscribed(users.ids[tt.subscriber], users.ids[tt.target])
+			if err != nil || !subscribed {
+				t.Errorf("IsSubscribed() = %v, %v, want true", subscribed, err)
+			}
+		})
+	}
+}
+
+func TestUnsubscribeUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		subscribe bool
+		wantErr   bool
+	}{
+		{"есть подписка", true, false},
+		{"нет подписки", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, users := newTestSubscriptionService(t)
+			alice, bob := users.ids["alice"], users.ids["bob"]
+			if tt.subscribe {
+				if err := s.SubscribeUser(alice, bob); err != nil {
+					t.Fatalf("SubscribeUser() error = %v", err)
+				}
+			}
+
+			if err := s.UnsubscribeUser(alice, bob); (err != nil) != tt.wantErr {
+				t.Fatalf("UnsubscribeUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if subscribed, _ := s.IsSubscribed(alice, bob); subscribed {
+				t.Error("IsSubscribed() = true после отписки")
+			}
+		})
+	}
+}
+
+func TestSubscriptionLists(t *testing.T) {
+	s, users := newTestSubscriptionService(t)
+	for _, sub := range [][2]string{{"alice", "bob"}, {"alice", "carol"}, {"carol", "bob"}} {
+		if err := s.SubscribeUser(users.ids[sub[0]], users.ids[sub[1]]); err != nil {
+			t.Fatalf("SubscribeUser(%s, %s) error = %v", sub[0], sub[1], err)
+		}
+	}
+
+	all, err := s.GetAllSubscriptions()
+	if err != nil {
+		t.Fatalf("GetAllSubscriptions() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		user            string
+		wantSubscribed  []string
+		wantSubscribers []string
+	}{
+		{"alice", "alice", []string{"bob", "carol"}, nil},
+		{"bob", "bob", nil, []string{"alice", "carol"}},
+		{"carol", "carol", []string{"bob"}, []string{"alice"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := users.ids[tt.user]
+			subscriptions, err := s.GetSubscriptions(id)
+			if err != nil {
+				t.Fatalf("GetSubscriptions() error = %v", err)
+			}
+			if got := usernames(subscriptions); !equal(got, tt.wantSubscribed) {
+				t.Errorf("GetSubscriptions() = %v, want %v", got, tt.wantSubscribed)
+			}
+			if got := usernames(all[id]); !equal(got, tt.wantSubscribed) {
+				t.Errorf("GetAllSubscriptions()[%s] = %v, want %v", tt.user, got, tt.wantSubscribed)
+			}
+
+			subscribers, err := s.GetSubscribersOf(id)
+			if err != nil {
+				t.Fatalf("GetSubscribersOf() error = %v", err)
+			}
+			if got := usernames(subscribers); !equal(got, tt.wantSubscribers) {
+				t.Errorf("GetSubscribersOf() = %v, want %v", got, tt.wantSubscribers)
+			}
+		})
+	}
+}
+
+func TestSubscriptionsDeletedWithUser(t *testing.T) {
+	s, users := newTestSubscriptionService(t)
+	alice, bob, carol := users.ids["alice"], users.ids["bob"], users.ids["carol"]
+	for _, sub := range [][2]int64{{alice, bob}, {bob, carol}, {carol, alice}} {
+		if err := s.SubscribeUser(sub[0], sub[1]); err != nil {
+			t.Fatalf("SubscribeUser() error = %v", err)
+		}
+	}
+
+	if err := users.repo.DeleteUser(bob); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	all, err := s.GetAllSubscriptions()
+	if err != nil {
+		t.Fatalf("GetAllSubscriptions() error = %v", err)
+	}
+	if got := usernames(all[alice]); len(got) != 0 {
+		t.Errorf("подписки alice = %v, want пусто", got)
+	}
+	if _, ok := all[bob]; ok {
+		t.Error("подписки удалённого пользователя остались")
+	}
+	if got := usernames(all[carol]); !equal(got, []string{"alice"}) {
+		t.Errorf("подписки carol = %v, want [alice]", got)
+	}
+}
+
+func usernames(users []models.UserBirthLayout) []string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
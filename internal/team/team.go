@@ -0,0 +1,250 @@
+package team
+
+import (
+	"BirthdayGreetings/internal/audit"
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/service"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	minNameLength = 2
+	maxNameLength = 64
+)
+
+// TeamService управляет командами и их участниками. Вступить в команду может
+// любой пользователь, исключать участников и назначать администраторов
+// команды могут только её администраторы.
+type TeamService struct {
+	repo        db.TeamRepository
+	userService *service.UserService
+	audit       *audit.AuditService
+}
+
+func NewTeamService(repo db.TeamRepository, userService *service.UserService, auditService *audit.AuditService) *TeamService {
+	return &TeamService{
+		repo:        repo,
+		userService: userService,
+		audit:       auditService,
+	}
+}
+
+// ValidateName проверяет название команды: от 2 до 64 символов, не начинается с /.
+func ValidateName(name string) error {
+	length := utf8.RuneCountInString(name)
+	if length < minNameLength || length > maxNameLength {
+		return errors.New(400, fmt.Sprintf("название команды должно быть от %d до %d символов", minNameLength, maxNameLength))
+	}
+	if strings.HasPrefix(name, "/") {
+		return errors.New(400, "название команды не может начинаться с /")
+	}
+	return nil
+}
+
+// Create создаёт команду, user становится её администратором.
+func (s *TeamService) Create(user *models.User, name string) (*models.Team, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	team := &models.Team{Name: name, CreatedBy: user.ID}
+	if err := s.repo.CreateTeam(team); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(audit.EventTeamCreated, user.ID, user.ID, team.Name)
+	return team, nil
+}
+
+func (s *TeamService) Join(user *models.User, name string) (*models.Team, error) {
+	team, err := s.repo.GetTeamByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddTeamMember(team.ID, user.ID); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(audit.EventTeamJoined, user.ID, user.ID, team.Name)
+	return team, nil
+}
+
+// Leave выводит user из команды. Последний администратор не может выйти,
+// пока в команде остаются другие участники.
+func (s *TeamService) Leave(user *models.User, name string) error {
+	team, members, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+
+	member, ok := findMember(members, user.ID)
+	if !ok {
+		return errors.New(404, "вы не состоите в этой команде")
+	}
+	if member.IsAdmin && len(members) > 1 && countAdmins(members) == 1 {
+		return errors.New(400, "вы единственный администратор команды, сначала назначьте другого командой /teamadmin")
+	}
+
+	if err := s.repo.RemoveTeamMember(team.ID, user.ID); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventTeamLeft, user.ID, user.ID, team.Name)
+	return nil
+}
+
+// RemoveMember исключает пользователя username из команды. Доступно
+// администраторам команды, других администраторов исключить нельзя.
+func (s *TeamService) RemoveMember(actor *models.User, name, username string) error {
+	team, target, err := s.adminAction(actor, name, username)
+	if err != nil {
+		return err
+	}
+	if target.IsAdmin {
+		return errors.New(403, "нельзя исключить администратора команды")
+	}
+
+	if err := s.repo.RemoveTeamMember(team.ID, target.UserID); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventTeamMemberRemoved, actor.ID, target.UserID, team.Name)
+	return nil
+}
+
+// GrantAdmin назначает участника username администратором команды. Доступно
+// администраторам команды.
+func (s *TeamService) GrantAdmin(actor *models.User, name, username string) error {
+	team, target, err := s.adminAction(actor, name, username)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SetTeamAdmin(team.ID, target.UserID, true); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.EventTeamAdminGranted, actor.ID, target.UserID, team.Name)
+	return nil
+}
+
+// HandOver передаёт команды, в которых user - единственный администратор,
+// участнику с наибольшим стажем. Вызывается перед удалением аккаунта, чтобы
+// команды не остались без администратора.
+func (s *TeamService) HandOver(user *models.User) error {
+	teams, err := s.repo.GetUserTeams(user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		members, err := s.repo.GetTeamMembers(team.ID)
+		if err != nil {
+			return err
+		}
+		member, ok := findMember(members, user.ID)
+		if !ok || !member.IsAdmin || countAdmins(members) > 1 {
+			continue
+		}
+
+		successor, ok := oldestMember(members, user.ID)
+		if !ok {
+			continue
+		}
+		if err := s.repo.SetTeamAdmin(team.ID, successor.UserID, true); err != nil {
+			return err
+		}
+		s.audit.Record(audit.EventTeamAdminGranted, 0, successor.UserID, team.Name)
+	}
+	return nil
+}
+
+// adminAction проверяет, что actor - администратор команды name, и находит
+// пользователя username среди других её участников.
+func (s *TeamService) adminAction(actor *models.User, name, username string) (*models.Team, models.TeamMember, error) {
+	team, members, err := s.Get(name)
+	if err != nil {
+		return nil, models.TeamMember{}, err
+	}
+	if member, ok := findMember(members, actor.ID); !ok || !member.IsAdmin {
+		return nil, models.TeamMember{}, errors.New(403, "это действие доступно только администраторам команды")
+	}
+
+	user, err := s.userService.GetUserByName(username)
+	if err != nil {
+		return nil, models.TeamMember{}, err
+	}
+	if user.ID == actor.ID {
+		return nil, models.TeamMember{}, errors.New(400, "нельзя выполнить это действие над собой")
+	}
+	target, ok := findMember(members, user.ID)
+	if !ok {
+		return nil, models.TeamMember{}, errors.New(404, "пользователь не состоит в команде")
+	}
+	return team, target, nil
+}
+
+// Get возвращает команду и её участников.
+func (s *TeamService) Get(name string) (*models.Team, []models.TeamMember, error) {
+	team, err := s.repo.GetTeamByName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := s.repo.GetTeamMembers(team.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return team, members, nil
+}
+
+func (s *TeamService) List() ([]models.Team, error) {
+	return s.repo.GetTeams()
+}
+
+// UserTeams возвращает команды, в которых состоит userID.
+func (s *TeamService) UserTeams(userID int64) ([]models.Team, error) {
+	return s.repo.GetUserTeams(userID)
+}
+
+func (s *TeamService) Members(teamID int64) ([]models.TeamMember, error) {
+	return s.repo.GetTeamMembers(teamID)
+}
+
+func findMember(members []models.TeamMember, userID int64) (models.TeamMember, bool) {
+	for _, m := range members {
+		if m.UserID == userID {
+			return m, true
+		}
+	}
+	return models.TeamMember{}, false
+}
+
+// oldestMember возвращает участника, раньше всех вступившего в команду, не
+// считая except.
+func oldestMember(members []models.TeamMember, except int64) (models.TeamMember, bool) {
+	var oldest models.TeamMember
+	found := false
+	for _, m := range members {
+		if m.UserID == except {
+			continue
+		}
+		if !found || m.JoinedAt.Before(oldest.JoinedAt) {
+			oldest, found = m, true
+		}
+	}
+	return oldest, found
+}
+
+func countAdmins(members []models.TeamMember) int {
+	admins := 0
+	for _, m := range members {
+		if m.IsAdmin {
+			admins++
+		}
+	}
+	return admins
+}
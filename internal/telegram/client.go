@@ -3,10 +3,13 @@ package telegram
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
+	"BirthdayGreetings/internal/config"
+
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/telegram/auth"
 	"github.com/gotd/td/tg"
@@ -14,34 +17,70 @@ import (
 
 type Client struct {
 	client *telegram.Client
+	cancel context.CancelFunc
+	done   chan error
 }
 
-func NewClient(appID int, appHash, phoneNumber, password string) (*Client, error) {
-	client := telegram.NewClient(appID, appHash, telegram.Options{})
+// NewClient подключается к Telegram и авторизуется. Соединение работает в фоне,
+// пока не будет вызван Close, и используется для вызовов API.
+func NewClient(cfg config.Telegram) (*Client, error) {
+	client := telegram.NewClient(cfg.AppID, cfg.AppHash, telegram.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Client{
+		client: client,
+		cancel: cancel,
+		done:   make(chan error, 1),
+	}
+	ready := make(chan struct{})
+
+	go func() {
+		c.done <- client.Run(ctx, func(ctx context.Context) error {
+			codePrompt := func(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
+				fmt.Print("Введите код для доступа в аккаунт: ")
+				code, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil {
+					return "", err
+				}
+				return strings.TrimSpace(code), nil
+			}
 
-	err := client.Run(context.Background(), func(ctx context.Context) error {
-		codePrompt := func(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
-			fmt.Print("Введите код для доступа в аккаунт: ")
-			code, err := bufio.NewReader(os.Stdin).ReadString('\n')
-			if err != nil {
-				return "", err
+			if err := auth.NewFlow(
+				auth.Constant(cfg.PhoneNumber, cfg.Password, auth.CodeAuthenticatorFunc(codePrompt)),
+				auth.SendCodeOptions{},
+			).Run(ctx, client.Auth()); err != nil {
+				return err
 			}
-			return strings.TrimSpace(code), nil
-		}
 
-		if err := auth.NewFlow(
-			auth.Constant(phoneNumber, password, auth.CodeAuthenticatorFunc(codePrompt)),
-			auth.SendCodeOptions{},
-		).Run(ctx, client.Auth()); err != nil {
-			panic(err)
+			close(ready)
+			<-ctx.Done()
+			return nil
+		})
+	}()
+
+	select {
+	case <-ready:
+		return c, nil
+	case err := <-c.done:
+		cancel()
+		return nil, err
+	}
+}
+
+// Close отключается от Telegram и ждёт завершения соединения, но не дольше,
+// чем позволяет ctx.
+func (c *Client) Close(ctx context.Context) error {
+	c.cancel()
+
+	select {
+	case err := <-c.done:
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
 		}
 		return nil
-	})
-	if err != nil {
-		panic(err)
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-
-	return &Client{client: client}, nil
 }
 
 func (c *Client) CreateChannel(ctx context.Context, title, about string) (*tg.Channel, error) {
@@ -59,43 +98,65 @@ func (c *Client) CreateChannel(ctx context.Context, title, about string) (*tg.Ch
 	return getChannelFromUpdates(updates)
 }
 
-func (c *Client) AddUsersToChannel(ctx context.Context, channel *tg.Channel, userIDs []int64) error {
-	api := c.client.API()
-	inputUsers := make([]tg.InputUserClass, len(userIDs))
+// InputChannel возвращает ссылку на канал для вызовов API по сохранённым ID и access hash.
+func InputChannel(channelID, accessHash int64) *tg.InputChannel {
+	return &tg.InputChannel{ChannelID: channelID, AccessHash: accessHash}
+}
 
-	for i, id := range userIDs {
-		user, err := api.UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUser{
-			UserID:     id,
-			AccessHash: 0,
-		}})
-		if err != nil {
-			return err
-		}
-		if len(user) == 0 {
-			return fmt.Errorf("пользователь не найден: %d", id)
-		}
+// SelfID возвращает ID аккаунта, от имени которого работает клиент.
+func (c *Client) SelfID(ctx context.Context) (int64, error) {
+	self, err := c.client.Self(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return self.ID, nil
+}
 
-		userClass := user[0].(*tg.User)
-		inputUsers[i] = &tg.InputUser{
-			UserID:     userClass.ID,
-			AccessHash: userClass.AccessHash,
-		}
+// InviteToChannel приглашает пользователя в канал.
+func (c *Client) InviteToChannel(ctx context.Context, channel *tg.InputChannel, userID int64) error {
+	user, err := c.inputUser(ctx, userID)
+	if err != nil {
+		return err
 	}
 
-	_, err := api.ChannelsInviteToChannel(ctx, &tg.ChannelsInviteToChannelRequest{
-		Channel: &tg.InputChannel{
-			ChannelID:  channel.ID,
-			AccessHash: channel.AccessHash,
-		},
-		Users: inputUsers,
+	_, err = c.client.API().ChannelsInviteToChannel(ctx, &tg.ChannelsInviteToChannelRequest{
+		Channel: channel,
+		Users:   []tg.InputUserClass{user},
 	})
 	return err
 }
 
-func (c *Client) AddBotToChannel(ctx context.Context, channel *tg.Channel, botID int64) error {
+// RemoveFromChannel исключает пользователя из канала. Бан сразу снимается,
+// чтобы пользователя можно было пригласить снова.
+func (c *Client) RemoveFromChannel(ctx context.Context, channel *tg.InputChannel, userID int64) error {
+	user, err := c.inputUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	peer := &tg.InputPeerUser{UserID: user.UserID, AccessHash: user.AccessHash}
+
+	api := c.client.API()
+	_, err = api.ChannelsEditBanned(ctx, &tg.ChannelsEditBannedRequest{
+		Channel:      channel,
+		Participant:  peer,
+		BannedRights: tg.ChatBannedRights{ViewMessages: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = api.ChannelsEditBanned(ctx, &tg.ChannelsEditBannedRequest{
+		Channel:      channel,
+		Participant:  peer,
+		BannedRights: tg.ChatBannedRights{},
+	})
+	return err
+}
+
+func (c *Client) AddBotToChannel(ctx context.Context, channel *tg.InputChannel, botID int64) error {
 	api := c.client.API()
 	_, err := api.ChannelsEditAdmin(ctx, &tg.ChannelsEditAdminRequest{
-		Channel: &tg.InputChannel{ChannelID: channel.ID, AccessHash: channel.AccessHash},
+		Channel: channel,
 		UserID:  &tg.InputUser{UserID: botID},
 		Rank:    "admin",
 		AdminRights: tg.ChatAdminRights{
@@ -107,6 +168,25 @@ func (c *Client) AddBotToChannel(ctx context.Context, channel *tg.Channel, botID
 	return err
 }
 
+func (c *Client) inputUser(ctx context.Context, userID int64) (*tg.InputUser, error) {
+	users, err := c.client.API().UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUser{
+		UserID:     userID,
+		AccessHash: 0,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("пользователь не найден: %d", userID)
+	}
+
+	user, ok := users[0].(*tg.User)
+	if !ok {
+		return nil, fmt.Errorf("пользователь не найден: %d", userID)
+	}
+	return &tg.InputUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+}
+
 func getChannelFromUpdates(updates tg.UpdatesClass) (*tg.Channel, error) {
 	switch u := updates.(type) {
 	case *tg.Updates:
@@ -0,0 +1,148 @@
+package wish
+
+import (
+	"BirthdayGreetings/internal/birthday"
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/service"
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxLength ограничивает длину одного пожелания в символах.
+const MaxLength = 1000
+
+// WishService собирает пожелания ко дню рождения. До дня рождения пожелания
+// видны только их авторам, в сам день они доставляются имениннику одной
+// открыткой и удаляются.
+type WishService struct {
+	repo        db.WishRepository
+	userService *service.UserService
+	matcher     *birthday.Matcher
+}
+
+func NewWishService(repo db.WishRepository, userService *service.UserService, matcher *birthday.Matcher) *WishService {
+	return &WishService{
+		repo:        repo,
+		userService: userService,
+		matcher:     matcher,
+	}
+}
+
+// Add записывает пожелание author к ближайшему дню рождения username и
+// возвращает его вместе с именинником. Повторное пожелание заменяет предыдущее.
+// Если день рождения сегодня, пожелания этого дня уже могли быть доставлены,
+// поэтому пожелание не сохраняется, а now = true: его нужно доставить сразу.
+func (s *WishService) Add(author *models.User, username, text string) (w *models.Wish, target *models.User, now bool, err error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil, false, errors.New(400, "пожелание не может быть пустым")
+	}
+	if utf8.RuneCountInString(text) > MaxLength {
+		return nil, nil, false, errors.New(400, fmt.Sprintf("пожелание должно быть не длиннее %d символов", MaxLength))
+	}
+
+	target, date, err := s.nextBirthday(author, username)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	w = &models.Wish{
+		UserID:     target.ID,
+		AuthorID:   author.ID,
+		AuthorName: author.Username,
+		Birthday:   date,
+		Text:       text,
+		CreatedAt:  time.Now(),
+	}
+	if s.matcher.IsToday(target.Birthday, time.Now()) {
+		return w, target, true, nil
+	}
+
+	if err := s.repo.SaveWish(w); err != nil {
+		return nil, nil, false, err
+	}
+	return w, target, false, nil
+}
+
+// Remove удаляет пожелание author к ближайшему дню рождения username.
+func (s *WishService) Remove(author *models.User, username string) error {
+	target, date, err := s.nextBirthday(author, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteWish(target.ID, author.ID, date)
+}
+
+// Pending возвращает недоставленные пожелания ко всем дням рождения до today
+// включительно, сгруппированные по имениннику и дате.
+func (s *WishService) Pending(today time.Time) ([]models.Wish, error) {
+	return s.repo.GetPendingWishes(today)
+}
+
+// Delivered удаляет доставленные пожелания ко дню рождения userID в date.
+func (s *WishService) Delivered(userID int64, date time.Time) error {
+	return s.repo.DeleteWishes(userID, date)
+}
+
+func (s *WishService) nextBirthday(author *models.User, username string) (*models.User, time.Time, error) {
+	target, err := s.userService.GetUserByName(username)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if target.ID == author.ID {
+		return nil, time.Time{}, errors.New(400, "нельзя написать пожелание самому себе")
+	}
+	if !birthday.IsSet(target.Birthday) {
+		return nil, time.Time{}, errors.New(400, target.Username+" ещё не указал дату рождения")
+	}
+	return target, s.matcher.Next(target.Birthday, time.Now()), nil
+}
+
+// Message собирает пожелания в одно сообщение.
+func Message(username string, wishes []models.Wish) string {
+	lines := []string{fmt.Sprintf("🎉 С днём рождения, %s! Вам написали пожелания:", username)}
+	for _, w := range wishes {
+		lines = append(lines, "", w.Text, "— "+w.AuthorName)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var cardTemplate = template.Must(template.New("card").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>С днём рождения, {{.Username}}!</title>
+<style>
+body { font-family: sans-serif; background: #fff7e6; margin: 0; padding: 2em; }
+h1 { text-align: center; color: #d2691e; }
+.wish { background: #fff; border-radius: 8px; padding: 1em 1.5em; margin: 1em auto; max-width: 40em; box-shadow: 0 1px 4px rgba(0,0,0,.15); }
+.text { white-space: pre-wrap; }
+.author { text-align: right; color: #777; margin-top: .5em; }
+</style>
+</head>
+<body>
+<h1>🎉 С днём рождения, {{.Username}}! 🎉</h1>
+<p style="text-align: center">{{.Date}}</p>
+{{range .Wishes}}<div class="wish"><div class="text">{{.Text}}</div><div class="author">— {{.AuthorName}}</div></div>
+{{end}}</body>
+</html>
+`))
+
+// Card собирает пожелания в HTML-открытку.
+func Card(username string, date time.Time, wishes []models.Wish) ([]byte, error) {
+	var buf bytes.Buffer
+	err := cardTemplate.Execute(&buf, struct {
+		Username string
+		Date     string
+		Wishes   []models.Wish
+	}{username, date.Format("02.01.2006"), wishes})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
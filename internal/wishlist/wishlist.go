@@ -0,0 +1,183 @@
+package wishlist
+
+import (
+	"BirthdayGreetings/internal/db"
+	"BirthdayGreetings/internal/errors"
+	"BirthdayGreetings/internal/models"
+	"BirthdayGreetings/internal/service"
+	"BirthdayGreetings/internal/subscription"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxItems ограничивает количество подарков в одном списке желаний.
+	MaxItems = 50
+	// MaxTitleLength - длина названия подарка в символах, совпадает с колонкой wishlist_items.title.
+	MaxTitleLength = 200
+)
+
+// WishlistService ведёт списки желаний. Список видят владелец и его
+// подписчики, подписчики могут бронировать подарки, чтобы не подарить
+// одно и то же. Владелец брони не видит.
+type WishlistService struct {
+	repo        db.WishlistRepository
+	userService *service.UserService
+	subService  *subscription.SubscriptionService
+}
+
+func NewWishlistService(repo db.WishlistRepository, userService *service.UserService, subService *subscription.SubscriptionService) *WishlistService {
+	return &WishlistService{
+		repo:        repo,
+		userService: userService,
+		subService:  subService,
+	}
+}
+
+// Add добавляет подарок в список желаний owner.
+func (s *WishlistService) Add(owner *models.User, title string) (*models.WishlistItem, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New(400, "название подарка не может быть пустым")
+	}
+	if utf8.RuneCountInString(title) > MaxTitleLength {
+		return nil, errors.New(400, fmt.Sprintf("название подарка должно быть не длиннее %d символов", MaxTitleLength))
+	}
+
+	items, err := s.repo.GetWishlist(owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) >= MaxItems {
+		return nil, errors.New(400, fmt.Sprintf("в списке желаний может быть не больше %d подарков", MaxItems))
+	}
+
+	item := &models.WishlistItem{UserID: owner.ID, Title: title}
+	if err := s.repo.AddWishlistItem(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Remove удаляет из списка желаний owner подарок itemID и возвращает его
+// вместе с бронью, чтобы забронировавшего можно было предупредить.
+func (s *WishlistService) Remove(owner *models.User, itemID int64) (*models.WishlistItem, error) {
+	item, err := s.repo.GetWishlistItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID != owner.ID {
+		return nil, errors.New(404, "подарок не найден")
+	}
+
+	if err := s.repo.DeleteWishlistItem(item.ID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Own возвращает список желаний owner без сведений о бронях.
+func (s *WishlistService) Own(owner *models.User) ([]models.WishlistItem, error) {
+	items, err := s.repo.GetWishlist(owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		hideReservation(&items[i])
+	}
+	return items, nil
+}
+
+// View возвращает владельца и список желаний username так, как его видит viewer.
+// Чужой список доступен только подписчикам владельца, свой - без сведений о бронях.
+func (s *WishlistService) View(viewer *models.User, username string) (*models.User, []models.WishlistItem, error) {
+	owner, err := s.userService.GetUserByName(username)
+	if err != nil {
+		return nil, nil, err
+	}
+	if owner.ID == viewer.ID {
+		items, err := s.Own(owner)
+		return owner, items, err
+	}
+
+	if err := s.checkSubscriber(viewer, owner); err != nil {
+		return nil, nil, err
+	}
+	items, err := s.repo.GetWishlist(owner.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return owner, items, nil
+}
+
+// Owners возвращает ID пользователей, в чьих списках желаний есть подарки.
+func (s *WishlistService) Owners() (map[int64]bool, error) {
+	return s.repo.GetWishlistOwners()
+}
+
+// Reserve бронирует подарок за viewer и возвращает владельца списка.
+func (s *WishlistService) Reserve(viewer *models.User, itemID int64) (*models.UserBirthLayout, error) {
+	owner, err := s.itemOwner(viewer, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReserveWishlistItem(itemID, viewer.ID, time.Now()); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
+
+// Release снимает бронь viewer с подарка и возвращает владельца списка.
+func (s *WishlistService) Release(viewer *models.User, itemID int64) (*models.UserBirthLayout, error) {
+	owner, err := s.itemOwner(viewer, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReleaseWishlistItem(itemID, viewer.ID); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
+
+// itemOwner проверяет, что viewer подписан на владельца подарка itemID,
+// и возвращает владельца из подписок viewer.
+func (s *WishlistService) itemOwner(viewer *models.User, itemID int64) (*models.UserBirthLayout, error) {
+	item, err := s.repo.GetWishlistItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID == viewer.ID {
+		return nil, errors.New(400, "нельзя бронировать подарки из своего списка")
+	}
+
+	subscriptions, err := s.subService.GetSubscriptions(viewer.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		if subscriptions[i].ID == item.UserID {
+			return &subscriptions[i], nil
+		}
+	}
+	return nil, errors.New(403, "бронировать подарки могут только подписчики владельца списка")
+}
+
+func (s *WishlistService) checkSubscriber(viewer, owner *models.User) error {
+	subscribed, err := s.subService.IsSubscribed(viewer.ID, owner.ID)
+	if err != nil {
+		return err
+	}
+	if !subscribed {
+		return errors.New(403, "список желаний "+owner.Username+" видят только его подписчики, подпишитесь командой /subscribe "+owner.Username)
+	}
+	return nil
+}
+
+// hideReservation убирает сведения о брони, чтобы владелец не узнал подарок заранее.
+func hideReservation(item *models.WishlistItem) {
+	item.ReservedBy = 0
+	item.ReservedName = ""
+	item.ReservedAt = time.Time{}
+}